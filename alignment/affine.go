@@ -0,0 +1,139 @@
+package alignment
+
+import "github.com/rschio/graph"
+
+// Layers of the affine graph.
+const (
+	// layerM holds the vertices reached by a diagonal edge.
+	layerM = iota
+	// layerI holds the vertices reached by a vertical edge,
+	// a rune of the sequence aligned with a space.
+	layerI
+	// layerD holds the vertices reached by a horizontal edge,
+	// a rune of the graph aligned with a space.
+	layerD
+	layers
+)
+
+// Affine is an alignment graph with affine gap costs, a gap of
+// length n costs Open + n*Extend instead of n*Score('A', space).
+// When the Score of the Graph charges the runes of the sequence
+// aligned to spaces more than the ones of the graph, each of them
+// costs the difference more, as with the linear gap costs.
+//
+// Each vertex of the underlying Graph is split in three layers,
+// one for each kind of edge that reaches it, so the search knows
+// if a gap is being opened or extended.
+type Affine struct {
+	G            *Graph
+	Src, Dst     int
+	Open, Extend int64
+	// size is the number of vertices of each layer.
+	size  int
+	order int
+}
+
+// Assert, in compile time, Affine satisfies
+// the graph.Iterator interface.
+var _ graph.Iterator = (*Affine)(nil)
+
+func NewAffine(g *Graph, open, extend int64) *Affine {
+	size := g.Order() - 2
+	order := layers*size + 2
+	return &Affine{
+		G:      g,
+		Src:    order - 2,
+		Dst:    order - 1,
+		Open:   open,
+		Extend: extend,
+		size:   size,
+		order:  order,
+	}
+}
+
+func (g *Affine) ShortestPath() (path []int, dist int64) {
+	max := g.G.MaxCost()
+	if c := g.gap(layerM, layerI); c > max {
+		max = c
+	}
+	q := newQueue(max)
+	return graph.ShortestPathWithQueue(g, q, g.Src, g.Dst)
+}
+
+func (g *Affine) Order() int {
+	return g.order
+}
+
+func (g *Affine) Visit(v int, do func(w int, c int64) bool) bool {
+	switch v {
+	case g.Dst:
+		return false
+	case g.Src:
+		// The alignment always starts with a diagonal edge.
+		return g.G.VisitFromSrc(do)
+	}
+	vertices := len(g.G.Labels)
+	layer := v / g.size
+	u := v % g.size
+	vertical := u + vertices
+	return g.G.Visit(u, func(w int, c int64) bool {
+		switch {
+		case w == g.G.Dst:
+			return do(g.Dst, c)
+		case w/vertices == u/vertices:
+			return do(layerD*g.size+w, g.gap(layer, layerD))
+		case w == vertical:
+			if do(layerI*g.size+w, g.gap(layer, layerI)) {
+				return true
+			}
+			// A vertex with loop has a diagonal edge
+			// sharing the vertical one.
+			if !g.G.loop(u % vertices) {
+				return false
+			}
+		}
+		return do(layerM*g.size+w, c)
+	})
+}
+
+// gap returns the cost of moving from layer from to the
+// gap layer to.
+func (g *Affine) gap(from, to int) int64 {
+	c := g.Extend
+	if from != to {
+		c += g.Open
+	}
+	if to == layerI {
+		c += g.G.Score('A', space) - g.G.Score(space, 'A')
+	}
+	return c
+}
+
+// Align projects the path to the underlying Graph
+// and aligns it.
+func (g *Affine) Align(path []int) (string, string) {
+	return g.G.Align(g.Project(path))
+}
+
+// Alignment projects the path to the underlying
+// Graph and returns its alignment.
+func (g *Affine) Alignment(path []int, dist int64) *Alignment {
+	return g.G.Alignment(g.Project(path), dist)
+}
+
+// Project maps a path of the affine graph to the
+// equivalent path of the underlying Graph.
+func (g *Affine) Project(path []int) []int {
+	p := make([]int, len(path))
+	for i, v := range path {
+		switch v {
+		case g.Src:
+			p[i] = g.G.Src
+		case g.Dst:
+			p[i] = g.G.Dst
+		default:
+			p[i] = v % g.size
+		}
+	}
+	return p
+}
@@ -0,0 +1,60 @@
+package alignment
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rschio/align/parse"
+)
+
+func chain(labels string) *parse.Graph {
+	pg := &parse.Graph{Nodes: []rune(labels)}
+	for i := 1; i < len(pg.Nodes); i++ {
+		pg.Edges = append(pg.Edges, [2]int{i - 1, i})
+	}
+	return pg
+}
+
+func TestAffineGapRun(t *testing.T) {
+	score := func(a, b rune) int64 {
+		switch {
+		case a == b:
+			return 0
+		case a == space || b == space:
+			return 1
+		}
+		return 10
+	}
+	pg := chain("AAAACCCCTTTTTT")
+	g := NewAffine(NewBase(pg, "AAAATTTTTT", score).Graph(), 3, 1)
+	path, dist := g.ShortestPath()
+	if dist != 3+4*1 {
+		t.Fatalf("invalid distance want: %d, got: %d", 7, dist)
+	}
+	s1, t1 := g.Align(path)
+	if s1 != "AAAACCCCTTTTTT" || t1 != "AAAA----TTTTTT" {
+		t.Fatalf("invalid alignment: got %s\n%s", s1, t1)
+	}
+}
+
+func TestAffineLinear(t *testing.T) {
+	dir := filepath.Join("testdata", "tests", "test8")
+	seq, err := readSequence(filepath.Join(dir, "seq.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	seq = strings.Replace(seq, seq[5:8], "", 1)
+	pg, err := readSeqGraph(filepath.Join(dir, "base_edges22.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewBase(pg, seq, weight).Graph()
+	_, want := g.ShortestPath()
+	// With no open cost the affine graph must
+	// find the same distance of the linear one.
+	_, got := NewAffine(g, 0, 1).ShortestPath()
+	if got != want {
+		t.Fatalf("invalid distance want: %d, got: %d", want, got)
+	}
+}
@@ -1,35 +1,184 @@
 package alignment
 
-func (g *Graph) Align(path []int) (string, string) {
-	s := make([]rune, len(path)-2)
-	t := make([]rune, len(path)-2)
+// Op is the operation of a column of the alignment.
+type Op byte
+
+const (
+	// Match aligns a rune of the sequence to
+	// an equal rune of the graph.
+	Match Op = '='
+	// Mismatch aligns a rune of the sequence to
+	// a different rune of the graph.
+	Mismatch Op = 'X'
+	// Insertion aligns a rune of the sequence to
+	// a space, it is a vertical edge.
+	Insertion Op = 'I'
+	// Deletion aligns a rune of the graph to
+	// a space, it is a horizontal edge.
+	Deletion Op = 'D'
+)
+
+// Alignment is the alignment of the sequence to a path
+// of the graph.
+type Alignment struct {
+	// Score is the cost of the alignment.
+	Score int64
+	// Nodes are the vertices of the graph visited by the
+	// alignment, they are indices of parse.Graph.Nodes.
+	Nodes []int
+	// SeqStart and SeqEnd delimit the interval [SeqStart, SeqEnd)
+	// of the sequence aligned, the runes out of it are clipped.
+	SeqStart, SeqEnd int
+	// Ops are the operations of each column.
+	Ops []Op
+	// Reverse tells if the sequence aligned is the reverse
+	// complement of the one given, its minus strand. SeqStart
+	// and SeqEnd are then positions of the reverse complement.
+	Reverse bool
+
+	labels    []rune
+	seqLabels []rune
+}
+
+// Alignment returns the alignment of the shortest path, from
+// Src to Dst, with distance dist. It returns nil if the path
+// is empty, when Dst cannot be reached. The vertices of the graph
+// before and after the path charged as spaces, when the graph
+// ends are not free, are deletions of the alignment.
+func (g *Graph) Alignment(path []int, dist int64) *Alignment {
+	if len(path) == 0 {
+		return nil
+	}
+	steps := g.Steps(path)
+	// The vertices of the graph ends charged as spaces
+	// are deletions out of the path.
+	before, after := g.graphGaps(path)
+	n := len(before) + len(steps) + len(after)
+	a := &Alignment{
+		Score:     dist,
+		Nodes:     make([]int, 0, n),
+		Ops:       make([]Op, 0, n),
+		labels:    g.Labels,
+		seqLabels: g.SeqLabels,
+	}
+	rowLen := len(g.Labels)
+	a.SeqStart = path[1] / rowLen
+	a.SeqEnd = path[len(path)-2]/rowLen + 1
+	for _, v := range before {
+		a.Ops = append(a.Ops, Deletion)
+		a.Nodes = append(a.Nodes, v)
+	}
+	for _, s := range steps {
+		a.Ops = append(a.Ops, s.Op)
+		if s.Node >= 0 {
+			a.Nodes = append(a.Nodes, s.Node)
+		}
+	}
+	for _, v := range after {
+		a.Ops = append(a.Ops, Deletion)
+		a.Nodes = append(a.Nodes, v)
+	}
+	return a
+}
+
+// Step is a column of the alignment, the operation with the
+// vertex of the graph and the index of the rune of the sequence
+// it aligns. Node is -1 in insertions and Seq is -1 in deletions.
+type Step struct {
+	Op   Op
+	Node int
+	Seq  int
+}
+
+// Steps classifies each edge of the shortest path, from Src to
+// Dst, in vertical, horizontal or diagonal and returns the
+// column of the alignment it makes.
+func (g *Graph) Steps(path []int) []Step {
 	rowLen := len(g.Labels)
 	// Ignore the fake nodes.
 	path = path[1 : len(path)-1]
-	seqn := g.SeqLabels
-	s[0] = g.Labels[path[0]]
-	t[0] = seqn[0]
-	seqn = seqn[1:]
+	steps := make([]Step, len(path))
 	prev := path[0]
-	j := 0
-	for i := 1; i < len(path); i++ {
-		v := path[i]
-		if v == prev+rowLen {
+	steps[0] = Step{Op: g.diagonal(prev), Node: prev % rowLen, Seq: prev / rowLen}
+	for i, v := range path[1:] {
+		vi := v % rowLen
+		row := v / rowLen
+		switch {
+		case v == prev+rowLen && !g.loop(vi):
 			// Vertical.
-			s[i] = space
-			t[i] = seqn[j]
-			j++
-		} else if prev/rowLen == v/rowLen {
+			steps[i+1] = Step{Op: Insertion, Node: -1, Seq: row}
+		case prev/rowLen == row:
 			// Horizontal.
-			s[i] = g.Labels[v%rowLen]
-			t[i] = space
-		} else {
-			// Diagonal.
-			s[i] = g.Labels[v%rowLen]
-			t[i] = seqn[j]
-			j++
+			steps[i+1] = Step{Op: Deletion, Node: vi, Seq: -1}
+		default:
+			// Diagonal, or the loop sharing
+			// the vertical edge.
+			steps[i+1] = Step{Op: g.diagonal(v), Node: vi, Seq: row}
 		}
 		prev = v
 	}
+	return steps
+}
+
+// diagonal returns the operation of the diagonal edge reaching v.
+func (g *Graph) diagonal(v int) Op {
+	rowLen := len(g.Labels)
+	if g.SeqLabels[v/rowLen] == g.Labels[v%rowLen] {
+		return Match
+	}
+	return Mismatch
+}
+
+// SeqLen returns the length of the sequence, with
+// the clipped runes.
+func (a *Alignment) SeqLen() int {
+	return len(a.seqLabels)
+}
+
+// Matches returns the number of matching columns.
+func (a *Alignment) Matches() int {
+	n := 0
+	for _, op := range a.Ops {
+		if op == Match {
+			n++
+		}
+	}
+	return n
+}
+
+// Strings returns the two rows of the alignment, the runes
+// of the graph and the runes of the sequence, with spaces
+// in the gaps. They are empty if a is nil.
+func (a *Alignment) Strings() (string, string) {
+	if a == nil {
+		return "", ""
+	}
+	s := make([]rune, len(a.Ops))
+	t := make([]rune, len(a.Ops))
+	i, j := 0, a.SeqStart
+	for k, op := range a.Ops {
+		s[k], t[k] = space, space
+		if op != Insertion {
+			s[k] = a.labels[a.Nodes[i]]
+			i++
+		}
+		if op != Deletion {
+			t[k] = a.seqLabels[j]
+			j++
+		}
+	}
 	return string(s), string(t)
 }
+
+// Align returns the two rows of the alignment of path, they
+// are empty if the path is empty, when Dst cannot be reached.
+func (g *Graph) Align(path []int) (string, string) {
+	return g.Alignment(path, 0).Strings()
+}
+
+// Clipped returns the interval [start, end) of the sequence
+// aligned by path, the runes out of it are clipped.
+func (g *Graph) Clipped(path []int) (start, end int) {
+	rowLen := len(g.Labels)
+	return path[1] / rowLen, path[len(path)-2]/rowLen + 1
+}
@@ -0,0 +1,96 @@
+package alignment
+
+import "testing"
+
+func TestAlignment(t *testing.T) {
+	score := func(a, b rune) int64 {
+		switch {
+		case a == b:
+			return 0
+		case a == space || b == space:
+			return 2
+		}
+		return 3
+	}
+	pg := chain("TGATACGAGT")
+	g := NewBase(pg, "TGTTAGACGT", score).Graph()
+	path, dist := g.ShortestPath()
+	a := g.Alignment(path, dist)
+	if a.Score != 7 {
+		t.Fatalf("invalid score want: %d, got: %d", 7, a.Score)
+	}
+	ops := "==X==D==I=="
+	if got := opsString(a.Ops); got != ops {
+		t.Fatalf("invalid ops want: %s, got: %s", ops, got)
+	}
+	if len(a.Nodes) != 10 {
+		t.Fatalf("invalid nodes want: 0 to 9, got: %v", a.Nodes)
+	}
+	for i, v := range a.Nodes {
+		if v != i {
+			t.Fatalf("invalid nodes want: 0 to 9, got: %v", a.Nodes)
+		}
+	}
+	if a.SeqStart != 0 || a.SeqEnd != 10 {
+		t.Fatalf("invalid interval want: [0, 10), got: [%d, %d)", a.SeqStart, a.SeqEnd)
+	}
+	if got, want := a.Cigar(), "2=1X2=1D2=1I2="; got != want {
+		t.Fatalf("invalid cigar want: %s, got: %s", want, got)
+	}
+	s1, t1 := a.Strings()
+	if s1 != "TGATACGA-GT" || t1 != "TGTTA-GACGT" {
+		t.Fatalf("invalid alignment: got %s\n%s", s1, t1)
+	}
+}
+
+func TestAlignUnreachable(t *testing.T) {
+	// A cycle has no start, so the Global alignment
+	// cannot reach Dst.
+	pg := chain("ACGT")
+	pg.Edges = append(pg.Edges, [2]int{3, 0})
+	g := NewBase(pg, "ACGT", mismatch2, WithEndGaps(Global)).Graph()
+	path, _ := g.ShortestPath()
+	if len(path) != 0 {
+		t.Fatalf("invalid path want: empty, got: %v", path)
+	}
+	if s1, t1 := g.Align(path); s1 != "" || t1 != "" {
+		t.Fatalf("invalid alignment want: empty, got: %s\n%s", s1, t1)
+	}
+}
+
+func opsString(ops []Op) string {
+	bs := make([]byte, len(ops))
+	for i, op := range ops {
+		bs[i] = byte(op)
+	}
+	return string(bs)
+}
+
+func TestCigar(t *testing.T) {
+	a := &Alignment{
+		SeqStart:  2,
+		SeqEnd:    10,
+		Ops:       []Op("==X==D==I="),
+		seqLabels: make([]rune, 13),
+	}
+	if got, want := a.Cigar(), "2S2=1X2=1D2=1I1=3S"; got != want {
+		t.Fatalf("invalid cigar want: %s, got: %s", want, got)
+	}
+	if got, want := a.CigarM(), "2S5M1D2M1I1M3S"; got != want {
+		t.Fatalf("invalid cigar want: %s, got: %s", want, got)
+	}
+}
+
+func TestCS(t *testing.T) {
+	a := &Alignment{
+		Nodes:     []int{0, 1, 2, 3, 4, 5, 6, 7},
+		SeqStart:  2,
+		SeqEnd:    9,
+		Ops:       []Op("==X=DD=I="),
+		labels:    []rune("ACGTACGT"),
+		seqLabels: []rune("TTACCTGATGG"),
+	}
+	if got, want := a.CS(), ":2*gc:1-ac:1+a:1"; got != want {
+		t.Fatalf("invalid cs want: %s, got: %s", want, got)
+	}
+}
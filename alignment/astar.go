@@ -0,0 +1,235 @@
+package alignment
+
+import "container/heap"
+
+// Heuristic returns a lower bound of the distance from
+// the vertex v to Dst.
+type Heuristic func(v int) int64
+
+// AStar is a Searcher expanding the vertices by their distance
+// plus the heuristic. The heuristic must never overestimate the
+// distance to Dst, then the distance found is the same of
+// Dijkstra. A vertex is expanded again when a shorter path to it
+// is found, so the heuristic need not be consistent.
+type AStar struct {
+	// Heuristic returns the heuristic of g, nil is
+	// the zero heuristic.
+	Heuristic func(g *Graph) Heuristic
+	dist      []int64
+	parent    []int
+	q         distQueue
+	// expanded is the number of vertices expanded
+	// by the last search.
+	expanded int
+}
+
+// NewAStar returns an AStar with the heuristic h.
+func NewAStar(h func(g *Graph) Heuristic) *AStar {
+	return &AStar{Heuristic: h}
+}
+
+func (a *AStar) ShortestPath(g *Graph) (path []int, dist int64) {
+	h := func(v int) int64 { return 0 }
+	if a.Heuristic != nil {
+		h = a.Heuristic(g)
+	}
+	n := g.Order()
+	if cap(a.dist) < n {
+		a.dist = make([]int64, n)
+		a.parent = make([]int, n)
+	}
+	a.dist, a.parent = a.dist[:n], a.parent[:n]
+	for i := range a.dist {
+		a.dist[i], a.parent[i] = -1, -1
+	}
+	a.q = a.q[:0]
+	a.expanded = 0
+
+	a.dist[g.Src] = 0
+	heap.Push(&a.q, distItem{g.Src, h(g.Src)})
+	var v int
+	do := func(w int, c int64) bool {
+		if c < 0 {
+			return false
+		}
+		alt := a.dist[v] + c
+		if a.dist[w] == -1 || alt < a.dist[w] {
+			a.dist[w], a.parent[w] = alt, v
+			heap.Push(&a.q, distItem{w, alt + h(w)})
+		}
+		return false
+	}
+	visit := g.visitor(do)
+	for a.q.Len() > 0 {
+		it := heap.Pop(&a.q).(distItem)
+		v = it.v
+		if it.d > a.dist[v]+h(v) {
+			// A shorter path to v was found
+			// after this one was queued.
+			continue
+		}
+		if v == g.Dst {
+			break
+		}
+		a.expanded++
+		visit(v)
+	}
+	path, dist = []int{}, a.dist[g.Dst]
+	if dist == -1 {
+		return path, dist
+	}
+	for v := g.Dst; v != -1; v = a.parent[v] {
+		path = append(path, v)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, dist
+}
+
+// clipper is implemented by the Interfaces that tell whether
+// the end of the sequence may be clipped, and its cost.
+type clipper interface {
+	clipEnd() (c int64, ok bool)
+}
+
+func (g *Base) clipEnd() (c int64, ok bool) {
+	return g.Clip, g.EndGaps&SeqEnd != 0
+}
+
+// SeedHeuristic returns the seed heuristic with seeds of k runes.
+// The sequence is split in seeds, and each seed no path of the
+// graph spells needs at least one edit, a mismatch, a gap or a
+// clipped rune, to be aligned. The heuristic of a vertex is the
+// number of such seeds after its row times the least cost of an
+// edit. It is zero when the Interface of the graph is unknown.
+func SeedHeuristic(k int) func(g *Graph) Heuristic {
+	return func(g *Graph) Heuristic {
+		zero := func(v int) int64 { return 0 }
+		cl, ok := g.Interface.(clipper)
+		if !ok || k < 1 {
+			return zero
+		}
+		edit := g.minEditCost()
+		if c, ok := cl.clipEnd(); ok && c < edit {
+			edit = c
+		}
+		if edit <= 0 {
+			return zero
+		}
+		// after[row] is the number of missing
+		// seeds starting after row.
+		rows := len(g.SeqLabels)
+		after := make([]int64, rows)
+		o := newOccurrences(g)
+		var missing int64
+		for row := rows - 1; row >= 0; row-- {
+			s := row + 1
+			if s%k == 0 && s+k <= rows && !o.spells(g.SeqLabels[s:s+k]) {
+				missing++
+			}
+			after[row] = missing
+		}
+		vertices := len(g.Labels)
+		return func(v int) int64 {
+			if v == g.Src || v == g.Dst {
+				return 0
+			}
+			return after[v/vertices] * edit
+		}
+	}
+}
+
+// minEditCost returns the least cost of an edit, a gap or
+// the alignment of a rune of the sequence to a different
+// rune of the graph.
+func (g *Graph) minEditCost() int64 {
+	min := g.Score('A', space)
+	if c := g.Score(space, 'A'); c < min {
+		min = c
+	}
+	labels := g.labelSet
+	if labels == nil {
+		labels = runeSet(g.Labels)
+	}
+	for _, a := range runeSet(g.SeqLabels) {
+		for _, b := range labels {
+			if c := g.Score(a, b); a != b && c < min {
+				min = c
+			}
+		}
+	}
+	return min
+}
+
+// occurrences finds the strings spelled by the paths of a graph.
+type occurrences struct {
+	g       *Graph
+	byLabel map[rune][]int
+	front   []int
+	next    []int
+	stamp   []int
+	round   int
+}
+
+func newOccurrences(g *Graph) *occurrences {
+	o := &occurrences{
+		g:       g,
+		byLabel: make(map[rune][]int),
+		stamp:   make([]int, len(g.Labels)),
+	}
+	for v, r := range g.Labels {
+		o.byLabel[r] = append(o.byLabel[r], v)
+	}
+	return o
+}
+
+// spells reports whether a path of the graph spells s,
+// following the vertices matching each prefix of s.
+func (o *occurrences) spells(s []rune) bool {
+	o.front = append(o.front[:0], o.byLabel[s[0]]...)
+	for _, r := range s[1:] {
+		if len(o.front) == 0 {
+			return false
+		}
+		o.round++
+		o.next = o.next[:0]
+		for _, v := range o.front {
+			o.g.successors(v, func(w int) {
+				if o.g.Labels[w] == r && o.stamp[w] != o.round {
+					o.stamp[w] = o.round
+					o.next = append(o.next, w)
+				}
+			})
+		}
+		o.front, o.next = o.next, o.front
+	}
+	return len(o.front) > 0
+}
+
+// successors calls do for each successor of the vertex vi of
+// the graph aligned, read from the diagonal edges and the loop.
+func (g *Graph) successors(vi int, do func(w int)) {
+	if g.nodes != nil {
+		if g.nodes.isLoop(vi) {
+			do(vi)
+		}
+		next, list := g.nodes.next(vi)
+		if next >= 0 {
+			do(next)
+		}
+		for _, w := range list {
+			do(w)
+		}
+		return
+	}
+	vertices := len(g.Labels)
+	if g.Loops[vi] {
+		do(vi)
+	}
+	for _, w := range g.Edges[vi] {
+		if w >= vertices && w != vi+vertices {
+			do(w - vertices)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package alignment
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/rschio/align/parse"
+)
+
+func TestAStar(t *testing.T) {
+	seqfile := filepath.Join("testdata", "benchdata", "sequence_data", "seq_100.txt")
+	seq, err := readSequence(seqfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	graphfile := filepath.Join("testdata", "benchdata", "graph_data", "graph_1000v_4d.txt")
+	pg, err := readSeqGraph(graphfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seqs := []string{seq, seq[:40] + "TTTT" + seq[50:], seq[7:9], "GATTACAGATTACA"}
+	opts := []Option{
+		WithEndGaps(Global),
+		WithEndGaps(Glocal),
+		WithEndGaps(Overlap),
+		WithEndGaps(LocalEnds),
+	}
+	for _, pg := range []*parse.Graph{pg, chain(seq)} {
+		for _, score := range []ScoreFn{weight, mismatch2} {
+			for i, opt := range opts {
+				for _, s := range seqs {
+					g := NewBase(pg, s, score, opt, WithClip(1)).Graph()
+					_, want := g.ShortestPath()
+					for _, k := range []int{4, 8} {
+						path, dist := NewAStar(SeedHeuristic(k)).ShortestPath(g)
+						if dist != want {
+							t.Fatalf("%d %q k=%d: invalid distance want: %d, got: %d",
+								i, s, k, want, dist)
+						}
+						if c := pathCost(g, path); dist != -1 && c != dist {
+							t.Fatalf("%d %q k=%d: invalid path cost want: %d, got: %d",
+								i, s, k, dist, c)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSeedHeuristicPrunes(t *testing.T) {
+	seqfile := filepath.Join("testdata", "benchdata", "sequence_data", "seq_100.txt")
+	ref, err := readSequence(seqfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pg := chain(ref)
+	g := NewBase(pg, ref[20:50]+"TTTTTTTTTTTTTTT", mismatch2).Graph()
+	dijkstra := NewAStar(nil)
+	_, want := dijkstra.ShortestPath(g)
+	seed := NewAStar(SeedHeuristic(5))
+	if _, dist := seed.ShortestPath(g); dist != want {
+		t.Fatalf("invalid distance want: %d, got: %d", want, dist)
+	}
+	if seed.expanded >= dijkstra.expanded {
+		t.Fatalf("seed heuristic expanded %d vertices, the zero heuristic %d",
+			seed.expanded, dijkstra.expanded)
+	}
+}
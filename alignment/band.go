@@ -0,0 +1,266 @@
+package alignment
+
+import "sort"
+
+// Band is the interval of vertices [Lo[row], Hi[row]] each
+// row of the sequence may be aligned to. The vertices are
+// the indices of the graph, so a band only follows the
+// alignment when they are close to a topological order,
+// as in the graphs read from text or from GFA.
+type Band struct {
+	Lo, Hi []int
+	// vertices is the number of vertices of the graph.
+	vertices int
+}
+
+// DiagonalBand returns the band of width vertices at each side
+// of the diagonal where row is aligned to the vertex row+diag,
+// as in a seed hit of the sequence at row to the vertex
+// row+diag.
+func DiagonalBand(rows, vertices, diag, width int) *Band {
+	b := newBand(rows, vertices)
+	for row := range b.Lo {
+		b.Lo[row] = row + diag - width
+		b.Hi[row] = row + diag + width
+	}
+	b.clamp()
+	return b
+}
+
+// PathBand returns the band of width vertices at each side of
+// the vertices of path, a shortest path of g. The rows the
+// path does not reach take the band of the nearest row.
+func PathBand(g *Graph, path []int, width int) *Band {
+	rows, vertices := len(g.SeqLabels), len(g.Labels)
+	b := newBand(rows, vertices)
+	for i := range b.Lo {
+		b.Lo[i], b.Hi[i] = -1, -1
+	}
+	for _, v := range path {
+		if v == g.Src || v == g.Dst {
+			continue
+		}
+		row, vi := v/vertices, v%vertices
+		if b.Lo[row] == -1 || vi < b.Lo[row] {
+			b.Lo[row] = vi
+		}
+		if vi > b.Hi[row] {
+			b.Hi[row] = vi
+		}
+	}
+	// Fill the rows out of the path, forward
+	// and then backward.
+	for row := 1; row < rows; row++ {
+		if b.Lo[row] == -1 {
+			b.Lo[row], b.Hi[row] = b.Lo[row-1], b.Hi[row-1]
+		}
+	}
+	for row := rows - 2; row >= 0; row-- {
+		if b.Lo[row] == -1 {
+			b.Lo[row], b.Hi[row] = b.Lo[row+1], b.Hi[row+1]
+		}
+	}
+	b.Widen(width)
+	return b
+}
+
+func newBand(rows, vertices int) *Band {
+	return &Band{
+		Lo:       make([]int, rows),
+		Hi:       make([]int, rows),
+		vertices: vertices,
+	}
+}
+
+// copy returns a copy of b.
+func (b *Band) copy() *Band {
+	c := newBand(len(b.Lo), b.vertices)
+	copy(c.Lo, b.Lo)
+	copy(c.Hi, b.Hi)
+	return c
+}
+
+// Contains reports whether the vertex vi may be aligned to row.
+func (b *Band) Contains(row, vi int) bool {
+	return vi >= b.Lo[row] && vi <= b.Hi[row]
+}
+
+// Widen widens the band by n vertices at each side.
+func (b *Band) Widen(n int) {
+	for row := range b.Lo {
+		b.Lo[row] -= n
+		b.Hi[row] += n
+	}
+	b.clamp()
+}
+
+// Width returns the largest number of vertices of a row.
+func (b *Band) Width() int {
+	w := 0
+	for row := range b.Lo {
+		if n := b.Hi[row] - b.Lo[row] + 1; n > w {
+			w = n
+		}
+	}
+	return w
+}
+
+// Full reports whether the band has every vertex of every row.
+func (b *Band) Full() bool {
+	for row := range b.Lo {
+		if b.Lo[row] > 0 || b.Hi[row] < b.vertices-1 {
+			return false
+		}
+	}
+	return true
+}
+
+// edge reports whether vi is at the edge of the band of row,
+// and there are vertices out of the band beyond it.
+func (b *Band) edge(row, vi int) bool {
+	return (vi == b.Lo[row] && vi > 0) ||
+		(vi == b.Hi[row] && vi < b.vertices-1)
+}
+
+func (b *Band) clamp() {
+	for row := range b.Lo {
+		if b.Lo[row] < 0 {
+			b.Lo[row] = 0
+		}
+		if b.Hi[row] > b.vertices-1 {
+			b.Hi[row] = b.vertices - 1
+		}
+	}
+}
+
+// filter returns do visiting only Dst and the
+// vertices in the band.
+func (b *Band) filter(vertices, dst int, do func(w int, c int64) bool) func(w int, c int64) bool {
+	return func(w int, c int64) bool {
+		if w != dst && !b.Contains(w/vertices, w%vertices) {
+			return false
+		}
+		return do(w, c)
+	}
+}
+
+// visitBand visits the edges of v to the vertices in g.Band.
+func (g *Graph) visitBand(v int, do func(w int, c int64) bool) bool {
+	return g.visit(v, g.Band.filter(len(g.Labels), g.Dst, do))
+}
+
+// visitor returns the function visiting the edges leaving a vertex
+// with do, as Visit, but the band filter is made only once, so
+// the searchers make it before their loops.
+func (g *Graph) visitor(do func(w int, c int64) bool) func(v int) bool {
+	if g.Band != nil {
+		do = g.Band.filter(len(g.Labels), g.Dst, do)
+	}
+	return func(v int) bool {
+		return g.visit(v, do)
+	}
+}
+
+// bandIndex numbers the vertices of a band, Src and Dst, so
+// the buffers of a search in the band are sized to the band.
+type bandIndex struct {
+	b *Band
+	// start[row] is the index of the vertex Lo[row] of row,
+	// start[rows] is the number of vertices of the band.
+	start              []int
+	vertices, src, dst int
+}
+
+func newBandIndex(g *Graph) *bandIndex {
+	b := g.Band
+	x := &bandIndex{
+		b:        b,
+		start:    make([]int, len(b.Lo)+1),
+		vertices: len(g.Labels),
+		src:      g.Src,
+		dst:      g.Dst,
+	}
+	for row := range b.Lo {
+		n := b.Hi[row] - b.Lo[row] + 1
+		if n < 0 {
+			n = 0
+		}
+		x.start[row+1] = x.start[row] + n
+	}
+	return x
+}
+
+// size returns the number of indices, the vertices
+// of the band, Src and Dst.
+func (x *bandIndex) size() int {
+	return x.start[len(x.start)-1] + 2
+}
+
+// index returns the index of the vertex v,
+// or -1 if v is out of the band.
+func (x *bandIndex) index(v int) int {
+	switch v {
+	case x.src:
+		return x.size() - 2
+	case x.dst:
+		return x.size() - 1
+	}
+	row, vi := v/x.vertices, v%x.vertices
+	if !x.b.Contains(row, vi) {
+		return -1
+	}
+	return x.start[row] + vi - x.b.Lo[row]
+}
+
+// vertex returns the vertex of the index i.
+func (x *bandIndex) vertex(i int) int {
+	switch i {
+	case x.size() - 2:
+		return x.src
+	case x.size() - 1:
+		return x.dst
+	}
+	row := sort.Search(len(x.b.Lo), func(row int) bool {
+		return x.start[row+1] > i
+	})
+	return row*x.vertices + x.b.Lo[row] + i - x.start[row]
+}
+
+// BandedShortestPath returns the shortest path found by s in
+// the band b. While the path touches the edge of the band, or
+// Dst is not reached, the band is doubled and the search is
+// repeated, so the path is the shortest one of a band wider
+// than its vertices. A band far from the best alignment may
+// still hold a worse path away from its edge. b is not changed,
+// the band doubled is a copy of it.
+func (g *Graph) BandedShortestPath(s Searcher, b *Band) (path []int, dist int64) {
+	old := g.Band
+	defer func() { g.Band = old }()
+	b = b.copy()
+	g.Band = b
+	for {
+		path, dist = s.ShortestPath(g)
+		if b.Full() || (dist != -1 && !b.touches(g, path)) {
+			return path, dist
+		}
+		w := b.Width()
+		if w < 1 {
+			w = 1
+		}
+		b.Widen(w)
+	}
+}
+
+// touches reports whether a vertex of path is at the edge of b.
+func (b *Band) touches(g *Graph, path []int) bool {
+	vertices := len(g.Labels)
+	for _, v := range path {
+		if v == g.Src || v == g.Dst {
+			continue
+		}
+		if b.edge(v/vertices, v%vertices) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package alignment
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestBandedShortestPath(t *testing.T) {
+	seqfile := filepath.Join("testdata", "benchdata", "sequence_data", "seq_100.txt")
+	ref, err := readSequence(seqfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pg := chain(ref)
+	seq := ref[60:75] + "GG" + ref[75:90]
+	g := NewBase(pg, seq, mismatch2).Graph()
+	_, want := g.ShortestPath()
+	rows, vertices := len(g.SeqLabels), len(g.Labels)
+
+	tests := []struct {
+		name string
+		band *Band
+		s    Searcher
+	}{
+		{name: "seed", band: DiagonalBand(rows, vertices, 60, 3), s: new(Dijkstra)},
+		// The insertion moves the path out of the band.
+		{name: "narrow seed", band: DiagonalBand(rows, vertices, 60, 0), s: new(Dijkstra)},
+		{name: "linear", band: DiagonalBand(rows, vertices, 60, 3), s: NewLinear(0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lo := append([]int(nil), tt.band.Lo...)
+			hi := append([]int(nil), tt.band.Hi...)
+			path, dist := g.BandedShortestPath(tt.s, tt.band)
+			if dist != want {
+				t.Fatalf("invalid distance want: %d, got: %d", want, dist)
+			}
+			if c := pathCost(g, path); c != dist {
+				t.Fatalf("invalid path cost want: %d, got: %d", dist, c)
+			}
+			for row := range lo {
+				if tt.band.Lo[row] != lo[row] || tt.band.Hi[row] != hi[row] {
+					t.Fatalf("band of row %d changed: [%d, %d]", row, tt.band.Lo[row], tt.band.Hi[row])
+				}
+			}
+			if g.Band != nil {
+				t.Fatal("band not restored")
+			}
+		})
+	}
+
+	path, _ := g.ShortestPath()
+	b := PathBand(g, path, 1)
+	if w := b.Width(); w > 4 {
+		t.Fatalf("invalid band width want at most: %d, got: %d", 4, w)
+	}
+	d := new(Dijkstra)
+	if _, dist := g.BandedShortestPath(d, b); dist != want {
+		t.Fatalf("invalid distance want: %d, got: %d", want, dist)
+	}
+	// The buffers are sized to the band, not to the graph.
+	if n := len(d.dist); n >= g.Order()/4 {
+		t.Fatalf("buffers not sized to the band: %d, order: %d", n, g.Order())
+	}
+}
@@ -6,6 +6,10 @@ import (
 
 const space = '-'
 
+// ScoreFn returns the cost of aligning the rune a of the sequence
+// to the rune b of the graph. A rune of the sequence aligned to a
+// space costs ScoreFn(a, '-') and a rune of the graph aligned to a
+// space costs ScoreFn('-', b).
 type ScoreFn func(a, b rune) int64
 
 type Base struct {
@@ -15,17 +19,38 @@ type Base struct {
 	SeqLabels []rune
 	Loops     []bool
 	Score     ScoreFn
-	order     int
+	// EndGaps tells which ends of the sequence and of the
+	// graph may be left out of the alignment.
+	EndGaps EndGaps
+	// Clip is the cost of each rune of the sequence
+	// left out of the alignment.
+	Clip  int64
+	order int
+	// depth and height are the distances from each vertex
+	// to the start and to the end of the graph.
+	depth, height []int
+	labelSet      []rune
+	// nodes, if not nil, are the edges of a graph of
+	// nodes labeled by strings, instead of Edges.
+	nodes *nodes
 }
 
-func NewBase(sg *parse.Graph, sequence string, score ScoreFn) *Base {
+// NewBase returns the alignment of sequence to sg, by default
+// the whole sequence is aligned to any path of sg (Glocal).
+func NewBase(sg *parse.Graph, sequence string, score ScoreFn, opts ...Option) *Base {
 	g := new(Base)
 	g.Score = score
+	g.EndGaps = Glocal
 	g.Labels = make([]rune, len(sg.Nodes))
 	copy(g.Labels, sg.Nodes)
+	g.labelSet = runeSet(g.Labels)
 
 	g.SetSeq(sequence)
 	g.Edges, g.Loops = normalizeEdges(sg.Edges, len(g.Labels))
+	g.depth, g.height = boundaryDist(sg.Edges, len(g.Labels))
+	for _, opt := range opts {
+		opt(g)
+	}
 	return g
 }
 
@@ -47,14 +72,38 @@ func (g *Base) Graph() *Graph {
 		Loops:     g.Loops,
 		Score:     g.Score,
 		order:     g.order,
+		labelSet:  g.labelSet,
+		nodes:     g.nodes,
 	}
 }
 
 func (g *Base) VisitFromSrc(do func(w int, c int64) bool) bool {
+	rows := 1
+	if g.EndGaps&SeqStart != 0 {
+		rows = len(g.SeqLabels)
+	}
+	for row := 0; row < rows; row++ {
+		if g.VisitFromSrcRow(row, do) {
+			return true
+		}
+	}
+	return false
+}
+
+// VisitFromSrcRow visits the edges leaving Src to the vertices of row.
+func (g *Base) VisitFromSrcRow(row int, do func(w int, c int64) bool) bool {
+	if row > 0 && g.EndGaps&SeqStart == 0 {
+		return false
+	}
 	neighbors := len(g.Labels)
+	offset := row * neighbors
 	for i := 0; i < neighbors; i++ {
-		c := g.Score(g.SeqLabels[0], g.Labels[i])
-		if do(i, c) {
+		c, ok := g.startCost(row, i)
+		if !ok {
+			continue
+		}
+		c += g.Score(g.SeqLabels[row], g.Labels[i])
+		if do(offset+i, c) {
 			return true
 		}
 	}
@@ -62,7 +111,55 @@ func (g *Base) VisitFromSrc(do func(w int, c int64) bool) bool {
 }
 
 func (g *Base) VisitFromLastRow(v int, do func(w int, c int64) bool) bool {
-	return do(g.Dst, 0)
+	vertices := len(g.Labels)
+	c, ok := g.endCost(v/vertices, v%vertices)
+	if !ok {
+		return false
+	}
+	return do(g.Dst, c)
+}
+
+// VisitFromRow visits Dst when the sequence may
+// end before the last row.
+func (g *Base) VisitFromRow(v int, do func(w int, c int64) bool) bool {
+	if g.EndGaps&SeqEnd == 0 {
+		return false
+	}
+	vertices := len(g.Labels)
+	c, ok := g.endCost(v/vertices, v%vertices)
+	if !ok {
+		return false
+	}
+	return do(g.Dst, c)
+}
+
+func (g *Base) MaxEndCost() int64 {
+	var c int64
+	if g.EndGaps&(SeqStart|SeqEnd) != 0 {
+		c = int64(len(g.SeqLabels)-1) * g.Clip
+	}
+	// The distances to the graph boundaries are only
+	// used when the graph ends are anchored.
+	d := 0
+	if g.EndGaps&GraphStart == 0 || g.EndGaps&(SeqStart|Unanchored) == SeqStart {
+		d = g.maxDepth()
+	}
+	if g.EndGaps&GraphEnd == 0 || g.EndGaps&(SeqEnd|Unanchored) == SeqEnd {
+		if h := g.maxHeight(); h > d {
+			d = h
+		}
+	}
+	return c + int64(d)*g.Score(space, 'A')
+}
+
+func maxInt(s []int) int {
+	max := 0
+	for _, v := range s {
+		if v > max {
+			max = v
+		}
+	}
+	return max
 }
 
 func normalizeEdges(es [][2]int, vertices int) ([][]int, []bool) {
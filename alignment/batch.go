@@ -0,0 +1,222 @@
+package alignment
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rschio/align/fastx"
+	"github.com/rschio/align/parse"
+)
+
+// Aligner aligns many sequences to the same graph. The edges
+// are normalized once and shared, read only, by the alignments,
+// so an Aligner can be used by many goroutines.
+type Aligner struct {
+	base *Base
+	// NewGraph returns the alignment graph of b, by
+	// default b.Graph().
+	NewGraph func(b *Base) *Graph
+	// NewSearcher returns the Searcher of a worker, by
+	// default a Dijkstra.
+	NewSearcher func() Searcher
+	// Strands, if true, aligns also the reverse complement of
+	// each sequence, as reads of DNA come from either strand,
+	// and keeps the alignment of lower score.
+	Strands bool
+}
+
+// NewAligner returns an Aligner of sequences to sg, the
+// options are the ones of NewBase.
+func NewAligner(sg *parse.Graph, score ScoreFn, opts ...Option) *Aligner {
+	return &Aligner{base: NewBase(sg, "", score, opts...)}
+}
+
+// Base returns a Base of sequence sharing the edges of a.
+func (a *Aligner) Base(sequence string) *Base {
+	b := *a.base
+	b.SetSeq(sequence)
+	return &b
+}
+
+func (a *Aligner) graph(sequence string) *Graph {
+	b := a.Base(sequence)
+	if a.NewGraph != nil {
+		return a.NewGraph(b)
+	}
+	return b.Graph()
+}
+
+func (a *Aligner) searcher() Searcher {
+	if a.NewSearcher != nil {
+		return a.NewSearcher()
+	}
+	return new(Dijkstra)
+}
+
+// Align returns the alignment of sequence using s, or
+// nil if the sequence cannot be aligned.
+func (a *Aligner) Align(sequence string, s Searcher) *Alignment {
+	if sequence == "" {
+		return nil
+	}
+	g := a.graph(sequence)
+	al := g.Alignment(s.ShortestPath(g))
+	if !a.Strands {
+		return al
+	}
+	g = a.graph(parse.ReverseComplement(sequence))
+	rev := g.Alignment(s.ShortestPath(g))
+	if rev != nil && (al == nil || rev.Score < al.Score) {
+		rev.Reverse = true
+		return rev
+	}
+	return al
+}
+
+// AlignAll aligns the sequences received from seqs on workers
+// goroutines, each with its own Searcher. The alignments are
+// sent to the returned channel in the order of seqs, which is
+// closed after seqs is closed and every sequence is aligned.
+// When ctx is done the goroutines stop and the channel is closed,
+// so a consumer that stops reading must cancel ctx.
+func (a *Aligner) AlignAll(ctx context.Context, seqs <-chan string, workers int) <-chan *Alignment {
+	if workers < 1 {
+		workers = 1
+	}
+	type job struct {
+		i   int
+		seq string
+	}
+	type result struct {
+		i int
+		a *Alignment
+	}
+	jobs := make(chan job, workers)
+	results := make(chan result, workers)
+	out := make(chan *Alignment, workers)
+
+	go func() {
+		defer close(jobs)
+		for i := 0; ; i++ {
+			var seq string
+			var ok bool
+			select {
+			case seq, ok = <-seqs:
+				if !ok {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- job{i: i, seq: seq}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			s := a.searcher()
+			for j := range jobs {
+				select {
+				case results <- result{i: j.i, a: a.Align(j.seq, s)}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Reorder the results, holding the ones
+	// finished before their predecessors.
+	go func() {
+		defer close(out)
+		pending := make(map[int]*Alignment)
+		next := 0
+		for r := range results {
+			pending[r.i] = r.a
+			for {
+				al, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				select {
+				case out <- al:
+				case <-ctx.Done():
+					return
+				}
+				next++
+			}
+		}
+	}()
+	return out
+}
+
+// RecordAlignment is the alignment of a record,
+// Alignment is nil if it cannot be aligned.
+type RecordAlignment struct {
+	Record    *fastx.Record
+	Alignment *Alignment
+}
+
+// AlignRecords aligns the sequences of the records received from
+// recs as AlignAll, sending each record with its alignment.
+func (a *Aligner) AlignRecords(ctx context.Context, recs <-chan *fastx.Record, workers int) <-chan RecordAlignment {
+	if workers < 1 {
+		workers = 1
+	}
+	seqs := make(chan string)
+	// pending holds the records being aligned, in order. Its
+	// size only bounds how far the reading goes ahead.
+	pending := make(chan *fastx.Record, 4*workers)
+	out := make(chan RecordAlignment, workers)
+	go func() {
+		defer close(seqs)
+		defer close(pending)
+		for {
+			var rec *fastx.Record
+			var ok bool
+			select {
+			case rec, ok = <-recs:
+				if !ok {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case pending <- rec:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case seqs <- rec.Seq:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	alignments := a.AlignAll(ctx, seqs, workers)
+	go func() {
+		defer close(out)
+		for al := range alignments {
+			r := RecordAlignment{Record: <-pending, Alignment: al}
+			select {
+			case out <- r:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,156 @@
+package alignment
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rschio/align/fastx"
+	"github.com/rschio/align/parse"
+)
+
+func TestAlignAll(t *testing.T) {
+	seqfile := filepath.Join("testdata", "benchdata", "sequence_data", "seq_100.txt")
+	seq, err := readSequence(seqfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	graphfile := filepath.Join("testdata", "benchdata", "graph_data", "graph_1000v_4d.txt")
+	pg, err := readSeqGraph(graphfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var seqs []string
+	for i := 0; i+20 <= len(seq); i += 5 {
+		seqs = append(seqs, seq[i:i+20], seq[i:i+10]+"TTT"+seq[i+10:i+20])
+	}
+	// An empty sequence cannot be aligned.
+	seqs = append(seqs, "")
+
+	for _, score := range []ScoreFn{weight, mismatch2} {
+		a := NewAligner(pg, score)
+		in := make(chan string)
+		go func() {
+			for _, s := range seqs {
+				in <- s
+			}
+			close(in)
+		}()
+		i := 0
+		for got := range a.AlignAll(context.Background(), in, 4) {
+			var want *Alignment
+			if seqs[i] != "" {
+				g := NewBase(pg, seqs[i], score).Graph()
+				want = g.Alignment(g.ShortestPath())
+			}
+			switch {
+			case want == nil && got != nil:
+				t.Fatalf("%d: want nil alignment, got score: %d", i, got.Score)
+			case want == nil:
+			case got == nil:
+				t.Fatalf("%d: want score: %d, got nil alignment", i, want.Score)
+			case got.Score != want.Score:
+				t.Fatalf("%d: invalid score want: %d, got: %d", i, want.Score, got.Score)
+			case got.SeqLen() != len(seqs[i]):
+				t.Fatalf("%d: invalid sequence length want: %d, got: %d",
+					i, len(seqs[i]), got.SeqLen())
+			}
+			i++
+		}
+		if i != len(seqs) {
+			t.Fatalf("invalid number of alignments want: %d, got: %d", len(seqs), i)
+		}
+	}
+}
+
+func TestAlignAllCancel(t *testing.T) {
+	a := NewAligner(chain("GGACGTACGTGG"), weight)
+	ctx, cancel := context.WithCancel(context.Background())
+	// The sequences only end when ctx is done.
+	in := make(chan string)
+	go func() {
+		for {
+			select {
+			case in <- "ACGTACGT":
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	out := a.AlignAll(ctx, in, 4)
+	<-out
+	cancel()
+	done := make(chan struct{})
+	go func() {
+		for range out {
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("alignments not stopped after cancel")
+	}
+}
+
+func TestAlignRecords(t *testing.T) {
+	pg := chain("GGACGTACGTGG")
+	recs := []*fastx.Record{
+		{Name: "r1", Seq: "ACGTACGT"},
+		{Name: "r2", Seq: "ACGTTACGT"},
+		{Name: "r3"},
+		{Name: "r4", Seq: "GACG"},
+	}
+	in := make(chan *fastx.Record)
+	go func() {
+		for _, rec := range recs {
+			in <- rec
+		}
+		close(in)
+	}()
+	a := NewAligner(pg, mismatch2)
+	i := 0
+	for got := range a.AlignRecords(context.Background(), in, 2) {
+		if got.Record != recs[i] {
+			t.Fatalf("%d: invalid record want: %s, got: %s", i, recs[i].Name, got.Record.Name)
+		}
+		if recs[i].Seq == "" {
+			if got.Alignment != nil {
+				t.Fatalf("%d: want nil alignment, got score: %d", i, got.Alignment.Score)
+			}
+			i++
+			continue
+		}
+		g := NewBase(pg, recs[i].Seq, mismatch2).Graph()
+		if _, want := g.ShortestPath(); got.Alignment == nil || got.Alignment.Score != want {
+			t.Fatalf("%d: invalid alignment want score: %d, got: %v", i, want, got.Alignment)
+		}
+		i++
+	}
+	if i != len(recs) {
+		t.Fatalf("invalid number of alignments want: %d, got: %d", len(recs), i)
+	}
+}
+
+func TestAlignStrands(t *testing.T) {
+	seqfile := filepath.Join("testdata", "benchdata", "sequence_data", "seq_100.txt")
+	ref, err := readSequence(seqfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := NewAligner(chain(ref), weight)
+	a.Strands = true
+	s := new(Dijkstra)
+	fwd := a.Align(ref[20:60], s)
+	if fwd.Score != 0 || fwd.Reverse {
+		t.Fatalf("forward: want score: 0, got: %d, reverse: %v", fwd.Score, fwd.Reverse)
+	}
+	rev := a.Align(parse.ReverseComplement(ref[20:60]), s)
+	if rev.Score != 0 || !rev.Reverse {
+		t.Fatalf("reverse: want score: 0, got: %d, reverse: %v", rev.Score, rev.Reverse)
+	}
+	if rev.Nodes[0] != 20 {
+		t.Fatalf("invalid first node want: 20, got: %d", rev.Nodes[0])
+	}
+}
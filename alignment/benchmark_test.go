@@ -67,3 +67,24 @@ func BenchmarkDial50(b *testing.B) {
 		})
 	}
 }
+
+func BenchmarkSearchers(b *testing.B) {
+	s := seqSize(b, 1000)
+	pg := readGraphSize(b, 10000)
+	searchers := []struct {
+		name string
+		s    Searcher
+	}{
+		{"Dijkstra", new(Dijkstra)},
+		{"BitParallel", new(BitParallel)},
+	}
+	for _, tt := range searchers {
+		b.Run(tt.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				g := NewBase(pg, s, weight).Graph()
+				_, _ = tt.s.ShortestPath(g)
+			}
+		})
+	}
+}
@@ -0,0 +1,489 @@
+package alignment
+
+import "math/bits"
+
+// sliceRows is the number of rows of a slice of the
+// bit-parallel search, the bits of a word.
+const sliceRows = 64
+
+// BitParallel is a Searcher of unit costs, where gaps cost 1 and
+// aligning two runes costs 0 or 1, for the Glocal alignment of a
+// Base. The rows of the alignment grid are split in slices of 64,
+// and the column of each vertex in a slice, the distances of its
+// rows, is computed from the columns of its predecessors with
+// Myers' bit-vector algorithm, as in GraphAligner. The columns of
+// a slice are swept in topological order, or close to it, until
+// none changes, so the graph may have cycles. Every column is kept
+// to trace back the path.
+//
+// The distances are capped by a bound, raised until the distance
+// found is below it, so only the columns with distances below the
+// bound keep changing. Every column is still computed once, while
+// Dijkstra only expands the vertices closer than the distance, so
+// Dijkstra may be faster for a sequence of few edits in a large
+// graph with many cycles.
+//
+// The graphs that don't fit are searched with Dijkstra.
+type BitParallel struct {
+	fallback Dijkstra
+}
+
+// Fits reports whether g can be searched bit-parallel.
+func (b *BitParallel) Fits(g *Graph) bool {
+	base, ok := g.Interface.(*Base)
+	if !ok || base.EndGaps != Glocal || g.Band != nil {
+		return false
+	}
+	if g.Score('A', space) != 1 || g.Score(space, 'A') != 1 {
+		return false
+	}
+	labels := g.labelSet
+	if labels == nil {
+		labels = runeSet(g.Labels)
+	}
+	for _, a := range runeSet(g.SeqLabels) {
+		for _, l := range labels {
+			if c := g.Score(a, l); c != 0 && c != 1 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (b *BitParallel) ShortestPath(g *Graph) (path []int, dist int64) {
+	if len(g.SeqLabels) == 0 || len(g.Labels) == 0 || !b.Fits(g) {
+		return b.fallback.ShortestPath(g)
+	}
+	s := newBitSearch(g)
+	// Every rune of the sequence may be aligned with cost 1,
+	// so the bound passes the distance before the length of
+	// the sequence.
+	for bound := int64(sliceRows); ; bound *= 4 {
+		s.search(bound)
+		if end, dist := s.end(); dist < bound {
+			return s.traceback(end, dist), dist
+		}
+	}
+}
+
+// column is the column of a vertex in a slice. The distance of the
+// row i of the slice is the distance of the row before the slice,
+// top, plus the bits up to i of vp, less the bits up to i of vn.
+type column struct {
+	vp, vn uint64
+}
+
+type bitSearch struct {
+	g        *Graph
+	vertices int
+	rows     int
+	bound    int64
+	// preds and succs are the predecessors and successors
+	// of each vertex, a vertex with a loop is its own.
+	preds [][]int
+	succs [][]int
+	// top[s][v] is the distance of the row before the
+	// slice s at v, and cols[s][v] is its column.
+	top  [][]int64
+	cols [][]column
+	// eq are the bits of the rows of the current slice
+	// aligned to each vertex with cost 0.
+	eq []uint64
+	// order are the vertices in reverse postorder, the
+	// columns are swept in order until none is dirty.
+	order []int
+	dirty []bool
+}
+
+func newBitSearch(g *Graph) *bitSearch {
+	n := len(g.Labels)
+	s := &bitSearch{
+		g:        g,
+		vertices: n,
+		rows:     len(g.SeqLabels),
+		preds:    make([][]int, n),
+		succs:    make([][]int, n),
+		eq:       make([]uint64, n),
+		dirty:    make([]bool, n),
+	}
+	for u := 0; u < n; u++ {
+		g.successors(u, func(w int) {
+			s.preds[w] = append(s.preds[w], u)
+			s.succs[u] = append(s.succs[u], w)
+		})
+	}
+	s.order = s.postorder()
+	slices := (s.rows + sliceRows - 1) / sliceRows
+	s.top = make([][]int64, slices)
+	s.cols = make([][]column, slices)
+	for i := range s.cols {
+		s.top[i] = make([]int64, n)
+		s.cols[i] = make([]column, n)
+	}
+	return s
+}
+
+// postorder returns the vertices in reverse postorder of a depth
+// first search, so the edges of a DAG go forward in the order.
+func (s *bitSearch) postorder() []int {
+	order := make([]int, 0, s.vertices)
+	seen := make([]bool, s.vertices)
+	type frame struct{ v, i int }
+	var stack []frame
+	for root := 0; root < s.vertices; root++ {
+		if seen[root] {
+			continue
+		}
+		seen[root] = true
+		stack = append(stack, frame{root, 0})
+		for len(stack) > 0 {
+			f := &stack[len(stack)-1]
+			if f.i < len(s.succs[f.v]) {
+				w := s.succs[f.v][f.i]
+				f.i++
+				if !seen[w] {
+					seen[w] = true
+					stack = append(stack, frame{w, 0})
+				}
+				continue
+			}
+			order = append(order, f.v)
+			stack = stack[:len(stack)-1]
+		}
+	}
+	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+		order[i], order[j] = order[j], order[i]
+	}
+	return order
+}
+
+// search computes the columns of every slice,
+// with the distances capped by bound.
+func (s *bitSearch) search(bound int64) {
+	s.bound = bound
+	for i := range s.cols {
+		s.slice(i)
+	}
+}
+
+// slice computes the columns of the slice i.
+func (s *bitSearch) slice(i int) {
+	top, cols := s.top[i], s.cols[i]
+	if i > 0 {
+		for v := range top {
+			top[v] = bottom(s.top[i-1][v], s.cols[i-1][v])
+		}
+	}
+	s.setEq(i * sliceRows)
+
+	// Start from the columns of the vertical edges,
+	// in the first slice the edges from Src reach
+	// the first row of every vertex.
+	for v := range cols {
+		c := column{vp: ^uint64(0)}
+		if i == 0 && s.eq[v]&1 != 0 {
+			c.vp &^= 1
+		}
+		cols[v] = s.capped(top[v], c)
+	}
+
+	for v := range s.dirty {
+		s.dirty[v] = true
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, u := range s.order {
+			if !s.dirty[u] {
+				continue
+			}
+			s.dirty[u] = false
+			// A column at the bound in every
+			// row improves no other column.
+			if top[u] >= s.bound && cols[u].vn == 0 {
+				continue
+			}
+			for _, w := range s.succs[u] {
+				if s.relax(top, cols, u, w) {
+					s.dirty[w], changed = true, true
+				}
+			}
+		}
+	}
+}
+
+// bottom returns the distance of the last row of c.
+func bottom(top int64, c column) int64 {
+	return top + int64(bits.OnesCount64(c.vp)) - int64(bits.OnesCount64(c.vn))
+}
+
+// setEq sets the bits of the rows of the slice starting
+// at first aligned to each vertex with cost 0.
+func (s *bitSearch) setEq(first int) {
+	last := first + sliceRows
+	if last > s.rows {
+		last = s.rows
+	}
+	eqs := make(map[rune]uint64)
+	for v, l := range s.g.Labels {
+		eq, ok := eqs[l]
+		if !ok {
+			for r := first; r < last; r++ {
+				if s.g.Score(s.g.SeqLabels[r], l) == 0 {
+					eq |= 1 << uint(r-first)
+				}
+			}
+			eqs[l] = eq
+		}
+		s.eq[v] = eq
+	}
+}
+
+// relax sets the column of w to the least distances of its
+// column and of the column from the edge u->w. It reports
+// whether the column of w changed.
+func (s *bitSearch) relax(top []int64, cols []column, u, w int) bool {
+	// The distances of the row before the slice differ by
+	// at most 1 in an edge, unless w is reached by a shorter
+	// path. Then the path of u is taken from a larger top,
+	// and the column of w keeps its own shorter paths.
+	hin := top[w] - top[u]
+	if hin < -1 {
+		hin = -1
+	}
+	vp, vn := myers(cols[u].vp, cols[u].vn, s.eq[w], hin)
+	return merge(&cols[w], top[w], column{vp, vn}, top[u]+hin)
+}
+
+// myers returns the column of a vertex from the column vp, vn
+// of its predecessor, where eq are the rows aligned to the vertex
+// with cost 0 and hin is the difference of their distances in the
+// row before the slice.
+func myers(vp, vn, eq uint64, hin int64) (uint64, uint64) {
+	xv := eq | vn
+	if hin < 0 {
+		eq |= 1
+	}
+	xh := (((eq & vp) + vp) ^ vp) | eq
+	ph := vn | ^(xh | vp)
+	mh := vp & xh
+	ph <<= 1
+	mh <<= 1
+	if hin < 0 {
+		mh |= 1
+	} else if hin > 0 {
+		ph |= 1
+	}
+	return mh | ^(xv | ph), ph & xv
+}
+
+// prefixes[x] holds in its byte i the number of bits of x up to i.
+var prefixes = prefixTable()
+
+func prefixTable() []uint64 {
+	t := make([]uint64, 256)
+	for x := range t {
+		var n uint64
+		for i := uint(0); i < 8; i++ {
+			n += uint64(x) >> i & 1
+			t[x] |= n << (8 * i)
+		}
+	}
+	return t
+}
+
+const (
+	lanes   = 0x0101010101010101
+	gatherL = 0x0102040810204080
+)
+
+// merge sets c, with distance top before the slice, to the least
+// distances of c and of d, with distance dtop. It reports whether
+// c changed. As c is capped by the bound so is the merge.
+//
+// The rows are merged a byte at a time. The differences of the
+// distances of d and c in the rows of a byte are computed at once,
+// each in a byte of a word, and the rows where d is less are taken
+// from d. Only the rows where the least column switches are read
+// one by one.
+func merge(c *column, top int64, d column, dtop int64) bool {
+	if d == *c && dtop == top {
+		return false
+	}
+	var vp, vn uint64
+	var from uint64 // rows taken from d
+	// a and b are the distances of c and d before the byte.
+	a, b := top, dtop
+	for i := uint(0); i < sliceRows; i += 8 {
+		cp, cn := c.vp>>i&0xff, c.vn>>i&0xff
+		dp, dn := d.vp>>i&0xff, d.vn>>i&0xff
+		var less uint64
+		switch diff := b - a; {
+		case diff > 16:
+		case diff < -16:
+			less = 0xff
+		default:
+			// Each byte is 64 plus the difference of a row, in
+			// [32, 96], so no byte borrows from the next one.
+			r := uint64(diff+64)*lanes + prefixes[dp] + prefixes[cn] -
+				prefixes[dn] - prefixes[cp]
+			less = (^r >> 6 & lanes) * gatherL >> 56
+		}
+		from |= less << i
+		vp |= (cp&^less | dp&less) << i
+		vn |= (cn&^less | dn&less) << i
+		a += int64(bits.OnesCount64(cp)) - int64(bits.OnesCount64(cn))
+		b += int64(bits.OnesCount64(dp)) - int64(bits.OnesCount64(dn))
+	}
+	if from == 0 {
+		return false
+	}
+	// In a row where the least column switches, the change
+	// of the distance is from the distance of the other one.
+	// The merge keeps the distance of c before the slice.
+	for sw := from ^ from<<1; sw != 0; sw &= sw - 1 {
+		i := uint(bits.TrailingZeros64(sw))
+		prev := top
+		if i > 0 {
+			prev = min64(rowDist(top, c.vp, c.vn, i-1), rowDist(dtop, d.vp, d.vn, i-1))
+		}
+		vp, vn = setDelta(vp, vn, i, prev, rowDist(top, c.vp, c.vn, i), rowDist(dtop, d.vp, d.vn, i))
+	}
+	c.vp, c.vn = vp, vn
+	return true
+}
+
+// rowDist returns the distance of the row i of the column vp, vn
+// with distance top before the slice.
+func rowDist(top int64, vp, vn uint64, i uint) int64 {
+	mask := ^uint64(0) >> (sliceRows - 1 - i)
+	return top + int64(bits.OnesCount64(vp&mask)) - int64(bits.OnesCount64(vn&mask))
+}
+
+// setDelta sets the bit i of vp, vn to the change from prev
+// to the least of x and y.
+func setDelta(vp, vn uint64, i uint, prev, x, y int64) (uint64, uint64) {
+	vp &^= 1 << i
+	vn &^= 1 << i
+	switch min64(x, y) - prev {
+	case 1:
+		vp |= 1 << i
+	case -1:
+		vn |= 1 << i
+	}
+	return vp, vn
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// capped returns c, with distance top before the slice, with its
+// distances capped by the bound. top must not pass the bound.
+func (s *bitSearch) capped(top int64, c column) column {
+	var vp, vn uint64
+	a, prev := top, top
+	for i := uint(0); i < sliceRows; i++ {
+		bit := uint64(1) << i
+		a += int64(c.vp>>i&1) - int64(c.vn>>i&1)
+		m := a
+		if m > s.bound {
+			m = s.bound
+		}
+		switch m - prev {
+		case 1:
+			vp |= bit
+		case -1:
+			vn |= bit
+		}
+		prev = m
+	}
+	return column{vp, vn}
+}
+
+// dist returns the distance of the row r at the vertex v.
+func (s *bitSearch) dist(r, v int) int64 {
+	i := r / sliceRows
+	mask := ^uint64(0) >> uint(sliceRows-1-r%sliceRows)
+	c := s.cols[i][v]
+	return s.top[i][v] + int64(bits.OnesCount64(c.vp&mask)) - int64(bits.OnesCount64(c.vn&mask))
+}
+
+// end returns the vertex of the last row with the least distance.
+func (s *bitSearch) end() (v int, dist int64) {
+	last := s.rows - 1
+	dist = s.dist(last, 0)
+	for w := 1; w < s.vertices; w++ {
+		if d := s.dist(last, w); d < dist {
+			v, dist = w, d
+		}
+	}
+	return v, dist
+}
+
+// cost returns the cost of aligning the row r to the vertex v.
+func (s *bitSearch) cost(r, v int) int64 {
+	return s.g.Score(s.g.SeqLabels[r], s.g.Labels[v])
+}
+
+// traceback returns the path from Src to Dst, ending at the
+// vertex end of the last row, with the least distance dist of
+// the row, below the bound. The last row has no horizontal
+// edges, but as end is the closest vertex of the row it is
+// not reached by one.
+//
+// The distances of the columns below the bound are the shortest
+// ones. Each one is the distance of a path, as the columns are
+// merged from paths of the grid, or from a larger top than the
+// one of the vertex, and a distance at the bound only leads to
+// larger ones. And each one is the least, as the sweeps stop when
+// no column improves, so a shortest path, whose vertices are below
+// the bound, has been relaxed edge by edge. So a vertex with a
+// distance below the bound always has a predecessor with the
+// distance less the cost of their edge, and the traceback only
+// fails if the search is broken.
+func (s *bitSearch) traceback(end int, dist int64) []int {
+	path := []int{s.g.Dst}
+	r, v, d := s.rows-1, end, dist
+	for {
+		path = append(path, r*s.vertices+v)
+		if r == 0 && d == s.cost(0, v) {
+			break
+		}
+		r, v, d = s.prev(r, v, d)
+	}
+	path = append(path, s.g.Src)
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// prev returns the vertex of the grid before the row r at the
+// vertex v, with distance d, in a shortest path.
+func (s *bitSearch) prev(r, v int, d int64) (int, int, int64) {
+	if r > 0 {
+		c := s.cost(r, v)
+		for _, u := range s.preds[v] {
+			if pd := s.dist(r-1, u); pd+c == d {
+				return r - 1, u, pd
+			}
+		}
+		// A vertex with a loop has no vertical edge,
+		// the loop is its diagonal.
+		if !s.g.loop(v) {
+			if pd := s.dist(r-1, v); pd+1 == d {
+				return r - 1, v, pd
+			}
+		}
+	}
+	for _, u := range s.preds[v] {
+		if pd := s.dist(r, u); pd+1 == d && u != v {
+			return r, u, pd
+		}
+	}
+	panic("alignment: broken bit-parallel traceback")
+}
@@ -0,0 +1,124 @@
+package alignment
+
+import (
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/rschio/align/parse"
+)
+
+func randomSeq(r *rand.Rand, n int) string {
+	const bases = "ACGT"
+	bs := make([]byte, n)
+	for i := range bs {
+		bs[i] = bases[r.Intn(len(bases))]
+	}
+	return string(bs)
+}
+
+// randomGraph returns a chain of the runes of ref with
+// extra edges, that may make cycles and loops.
+func randomGraph(r *rand.Rand, ref string, extra int) *parse.Graph {
+	pg := chain(ref)
+	for i := 0; i < extra; i++ {
+		u, w := r.Intn(len(ref)), r.Intn(len(ref))
+		pg.Edges = append(pg.Edges, [2]int{u, w})
+	}
+	return pg
+}
+
+func TestBitParallel(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 300; i++ {
+		ref := randomSeq(r, 10+r.Intn(150))
+		pg := randomGraph(r, ref, r.Intn(10))
+		start := r.Intn(len(ref) / 2)
+		s := []rune(ref[start:])
+		for j := 0; j < len(s)/10; j++ {
+			s[r.Intn(len(s))] = 'T'
+		}
+		seq := string(s) + randomSeq(r, r.Intn(20))
+		g := NewBase(pg, seq, weight).Graph()
+		_, want := g.ShortestPath()
+		path, dist := new(BitParallel).ShortestPath(g)
+		if dist != want {
+			t.Fatalf("%d: invalid distance want: %d, got: %d", i, want, dist)
+		}
+		if c := pathCost(g, path); c != dist {
+			t.Fatalf("%d: invalid path cost want: %d, got: %d", i, dist, c)
+		}
+	}
+}
+
+func TestBitParallelTestdata(t *testing.T) {
+	seqfile := filepath.Join("testdata", "benchdata", "sequence_data", "seq_100.txt")
+	seq, err := readSequence(seqfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	graphfile := filepath.Join("testdata", "benchdata", "graph_data", "graph_1000v_4d.txt")
+	pg, err := readSeqGraph(graphfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		score ScoreFn
+		fits  bool
+	}{
+		{name: "unit", score: weight, fits: true},
+		{name: "mismatch 2", score: mismatch2, fits: false},
+	}
+	for _, tt := range tests {
+		g := NewBase(pg, seq, tt.score).Graph()
+		_, want := g.ShortestPath()
+		b := new(BitParallel)
+		if fits := b.Fits(g); fits != tt.fits {
+			t.Fatalf("%s: invalid fit want: %v, got: %v", tt.name, tt.fits, fits)
+		}
+		path, dist := b.ShortestPath(g)
+		if dist != want {
+			t.Fatalf("%s: invalid distance want: %d, got: %d", tt.name, want, dist)
+		}
+		if c := pathCost(g, path); c != dist {
+			t.Fatalf("%s: invalid path cost want: %d, got: %d", tt.name, dist, c)
+		}
+	}
+}
+
+func TestBitParallelTraceback(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for i := 0; i < 500; i++ {
+		ref := randomSeq(r, 10+r.Intn(100))
+		pg := randomGraph(r, ref, r.Intn(30))
+		// Random sequences are far from the graph, the
+		// ones of the graph with few edits are close.
+		seq := randomSeq(r, 1+r.Intn(3*sliceRows))
+		if r.Intn(2) == 0 {
+			s := []rune(ref[r.Intn(len(ref)/2):])
+			for j := 0; j < len(s)/10; j++ {
+				s[r.Intn(len(s))] = 'T'
+			}
+			seq = string(s)
+		}
+		g := NewBase(pg, seq, weight).Graph()
+		_, want := g.ShortestPath()
+		s := newBitSearch(g)
+		// The path is traced back at every bound
+		// above the distance, not only the first.
+		for _, bound := range []int64{sliceRows / 4, sliceRows, 4 * sliceRows} {
+			s.search(bound)
+			end, dist := s.end()
+			if dist >= bound {
+				continue
+			}
+			if dist != want {
+				t.Fatalf("%d: invalid distance want: %d, got: %d", i, want, dist)
+			}
+			if c := pathCost(g, s.traceback(end, dist)); c != dist {
+				t.Fatalf("%d: invalid path cost want: %d, got: %d", i, dist, c)
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package alignment
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// SoftClip is the CIGAR operation of the runes
+// of the sequence out of the alignment.
+const SoftClip Op = 'S'
+
+// Cigar returns the CIGAR of the alignment with the operations
+// =, X, I, D and S, for the clipped runes of the sequence.
+func (a *Alignment) Cigar() string {
+	return a.cigar(func(op Op) Op { return op }, true)
+}
+
+// AlignedCigar returns the CIGAR of the alignment as Cigar but
+// without the clipped runes, which are given by the sequence start
+// and end, as in the cg tag of GAF.
+func (a *Alignment) AlignedCigar() string {
+	return a.cigar(func(op Op) Op { return op }, false)
+}
+
+// CigarM returns the CIGAR of the alignment with the operations
+// M, I, D and S, matches and mismatches are both M.
+func (a *Alignment) CigarM() string {
+	return a.cigar(func(op Op) Op {
+		if op == Match || op == Mismatch {
+			return 'M'
+		}
+		return op
+	}, true)
+}
+
+func (a *Alignment) cigar(conv func(op Op) Op, clip bool) string {
+	var b strings.Builder
+	write := func(n int, op Op) {
+		if n == 0 {
+			return
+		}
+		b.WriteString(strconv.Itoa(n))
+		b.WriteByte(byte(op))
+	}
+	if clip {
+		write(a.SeqStart, SoftClip)
+	}
+	n := 0
+	var last Op
+	for _, op := range a.Ops {
+		op = conv(op)
+		if op != last {
+			write(n, last)
+			n = 0
+			last = op
+		}
+		n++
+	}
+	write(n, last)
+	if clip {
+		write(len(a.seqLabels)-a.SeqEnd, SoftClip)
+	}
+	return b.String()
+}
+
+// CS returns the short form of the cs difference string of the
+// alignment, without the clipped runes: :n for n matches, *gs for a
+// rune g of the graph aligned to a different rune s of the sequence,
+// +s for runes of the sequence aligned to spaces and -g for runes of
+// the graph aligned to spaces. The runes are in lower case.
+func (a *Alignment) CS() string {
+	var b strings.Builder
+	matches := 0
+	var last Op
+	i, j := 0, a.SeqStart
+	for _, op := range a.Ops {
+		if op != Match && matches > 0 {
+			b.WriteByte(':')
+			b.WriteString(strconv.Itoa(matches))
+			matches = 0
+		}
+		switch op {
+		case Match:
+			matches++
+		case Mismatch:
+			b.WriteByte('*')
+			b.WriteRune(unicode.ToLower(a.labels[a.Nodes[i]]))
+			b.WriteRune(unicode.ToLower(a.seqLabels[j]))
+		case Insertion:
+			if last != Insertion {
+				b.WriteByte('+')
+			}
+			b.WriteRune(unicode.ToLower(a.seqLabels[j]))
+		case Deletion:
+			if last != Deletion {
+				b.WriteByte('-')
+			}
+			b.WriteRune(unicode.ToLower(a.labels[a.Nodes[i]]))
+		}
+		if op != Insertion {
+			i++
+		}
+		if op != Deletion {
+			j++
+		}
+		last = op
+	}
+	if matches > 0 {
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(matches))
+	}
+	return b.String()
+}
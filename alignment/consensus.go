@@ -0,0 +1,96 @@
+package alignment
+
+import (
+	"fmt"
+
+	"github.com/rschio/align/parse"
+	"github.com/rschio/graph"
+)
+
+// Weights counts the sequences aligned through each edge of
+// a graph, the edges are pairs of indices of parse.Graph.Nodes.
+type Weights map[[2]int]int
+
+// Add counts the edges of the graph aligned visited by path, the
+// shortest path of g from Src to Dst.
+func (w Weights) Add(g *Graph, path []int) {
+	if len(path) == 0 {
+		return
+	}
+	var nodes []int
+	for _, s := range g.Steps(path) {
+		if s.Node >= 0 {
+			nodes = append(nodes, s.Node)
+		}
+	}
+	w.AddNodes(nodes)
+}
+
+// AddNodes counts the edges between consecutive nodes, as
+// the ones returned by Fold.
+func (w Weights) AddNodes(nodes []int) {
+	for i := 1; i < len(nodes); i++ {
+		w[[2]int{nodes[i-1], nodes[i]}]++
+	}
+}
+
+// Consensus returns the heaviest path of sg, the path with the
+// largest sum of weights, and the runes it spells. sg must not
+// have cycles, but loops are ignored.
+func (w Weights) Consensus(sg *parse.Graph) (string, []int, error) {
+	out := make(adjacency, len(sg.Nodes))
+	for _, e := range sg.Edges {
+		if e[0] != e[1] {
+			out[e[0]] = append(out[e[0]], e[1])
+		}
+	}
+	order, ok := graph.TopSort(out)
+	if !ok {
+		return "", nil, fmt.Errorf("invalid graph: it has cycles")
+	}
+	if len(order) == 0 {
+		return "", nil, nil
+	}
+	score := make([]int, len(sg.Nodes))
+	parent := make([]int, len(sg.Nodes))
+	for i := range parent {
+		parent[i] = -1
+	}
+	end := order[0]
+	for _, v := range order {
+		if score[v] > score[end] {
+			end = v
+		}
+		for _, u := range out[v] {
+			if s := score[v] + w[[2]int{v, u}]; s > score[u] {
+				score[u], parent[u] = s, v
+			}
+		}
+	}
+	var nodes []int
+	for v := end; v != -1; v = parent[v] {
+		nodes = append(nodes, v)
+	}
+	rs := make([]rune, len(nodes))
+	for i, j := 0, len(nodes)-1; i <= j; i, j = i+1, j-1 {
+		nodes[i], nodes[j] = nodes[j], nodes[i]
+		rs[i], rs[j] = sg.Nodes[nodes[i]], sg.Nodes[nodes[j]]
+	}
+	return string(rs), nodes, nil
+}
+
+// adjacency is a graph.Iterator of lists of out edges.
+type adjacency [][]int
+
+func (a adjacency) Order() int {
+	return len(a)
+}
+
+func (a adjacency) Visit(v int, do func(w int, c int64) bool) bool {
+	for _, w := range a[v] {
+		if do(w, 0) {
+			return true
+		}
+	}
+	return false
+}
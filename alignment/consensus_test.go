@@ -0,0 +1,49 @@
+package alignment
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestConsensus(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	ref := randomSeq(r, 300)
+	var reads []string
+	for i := 0; i < 15; i++ {
+		s := []rune(ref)
+		for j := 0; j < 6; j++ {
+			s[r.Intn(len(s))] = rune("ACGT"[r.Intn(4)])
+		}
+		cut := r.Intn(len(s))
+		reads = append(reads, string(s[:cut])+randomSeq(r, 2)+string(s[cut:]))
+	}
+	pg := chain(reads[0])
+	for _, read := range reads[1:] {
+		g := NewBase(pg, read, weight, WithEndGaps(Global)).Graph()
+		Fold(pg, g.Alignment(new(POA).ShortestPath(g)))
+	}
+	w := make(Weights)
+	for _, read := range reads {
+		g := NewBase(pg, read, weight, WithEndGaps(Global)).Graph()
+		path, _ := new(POA).ShortestPath(g)
+		w.Add(g, path)
+	}
+	s, nodes, err := w.Consensus(pg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != ref {
+		t.Fatalf("invalid consensus want: %s, got: %s", ref, s)
+	}
+	if got := spell(pg, nodes); got != s {
+		t.Fatalf("invalid nodes want: %s, got: %s", s, got)
+	}
+}
+
+func TestConsensusCycles(t *testing.T) {
+	pg := chain("ACGT")
+	pg.Edges = append(pg.Edges, [2]int{3, 0})
+	if _, _, err := make(Weights).Consensus(pg); err == nil {
+		t.Fatal("want error, got nil")
+	}
+}
@@ -1,10 +1,19 @@
 package alignment
 
+import "sort"
+
 type DBG struct {
 	*Base
 	K int
+	// starts are the first vertex of each chain of runes and the
+	// number of vertices, nil if every chain has K runes.
+	starts []int
 }
 
+// NewDBG returns the alignment of a de Bruijn graph expanded by
+// Parse, g must be a Base of a parse.Graph, not of a SeqGraph.
+// The alignment starts at the first rune of a k-mer and ends at
+// the last rune of one, the end gaps of g apply there.
 func NewDBG(g *Base, k int) *DBG {
 	return &DBG{
 		Base: g,
@@ -12,6 +21,19 @@ func NewDBG(g *Base, k int) *DBG {
 	}
 }
 
+// NewUnitigDBG returns the alignment of a de Bruijn graph expanded
+// by ParseUnitigs, where the chains of runes are unitigs. starts are
+// the first vertex of each chain and the number of vertices, as
+// returned by ParseGraph Starts. The alignment starts at the first
+// rune of any k-mer of a chain and ends at the last rune of one.
+func NewUnitigDBG(g *Base, k int, starts []int) *DBG {
+	return &DBG{
+		Base:   g,
+		K:      k,
+		starts: starts,
+	}
+}
+
 func (g *DBG) Graph() *Graph {
 	nGraph := g.Base.Graph()
 	nGraph.Interface = g
@@ -19,12 +41,49 @@ func (g *DBG) Graph() *Graph {
 
 }
 
+// VisitFromSrc visits the edges leaving Src to the first runes of
+// the k-mers, of the first row or, if the sequence start may be
+// clipped, of every row.
 func (g *DBG) VisitFromSrc(do func(w int, c int64) bool) bool {
-	n := len(g.Labels)
-	k := g.K
-	for i := 0; i < n; i += k {
-		c := g.Score(g.SeqLabels[0], g.Labels[i])
-		if do(i, c) {
+	rows := 1
+	if g.EndGaps&SeqStart != 0 {
+		rows = len(g.SeqLabels)
+	}
+	for row := 0; row < rows; row++ {
+		if g.VisitFromSrcRow(row, do) {
+			return true
+		}
+	}
+	return false
+}
+
+// VisitFromSrcRow visits the edges leaving Src to the first runes
+// of the k-mers of row, with the end gap costs of the Base.
+func (g *DBG) VisitFromSrcRow(row int, do func(w int, c int64) bool) bool {
+	if row > 0 && g.EndGaps&SeqStart == 0 {
+		return false
+	}
+	offset := row * len(g.Labels)
+	visit := func(i int) bool {
+		c, ok := g.startCost(row, i)
+		if !ok {
+			return false
+		}
+		return do(offset+i, c+g.Score(g.SeqLabels[row], g.Labels[i]))
+	}
+	if g.starts != nil {
+		for c := 1; c < len(g.starts); c++ {
+			// The k-mers of a chain start up to its last k runes.
+			for i := g.starts[c-1]; i <= g.starts[c]-g.K; i++ {
+				if visit(i) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	for i := 0; i < len(g.Labels); i += g.K {
+		if visit(i) {
 			return true
 		}
 	}
@@ -41,13 +100,42 @@ func (g *DBG) VisitFromLastRow(v int, do func(w int, c int64) bool) bool {
 			break
 		}
 		w += offset
-		c := g.Score('A', space)
+		c := g.Score(space, 'A')
 		if do(w, c) {
 			return true
 		}
 	}
-	if v%g.K == g.K-1 {
-		return do(g.Dst, 0)
+	return g.visitEnd(v, do)
+}
+
+// VisitFromRow visits Dst when the sequence may
+// end before the last row.
+func (g *DBG) VisitFromRow(v int, do func(w int, c int64) bool) bool {
+	if g.EndGaps&SeqEnd == 0 {
+		return false
 	}
-	return false
+	return g.visitEnd(v, do)
+}
+
+// visitEnd visits the edge from v to Dst, if v is the last rune of
+// a k-mer, with the end gap costs of the Base.
+func (g *DBG) visitEnd(v int, do func(w int, c int64) bool) bool {
+	vertices := len(g.Labels)
+	if !g.isEnd(v % vertices) {
+		return false
+	}
+	c, ok := g.endCost(v/vertices, v%vertices)
+	if !ok {
+		return false
+	}
+	return do(g.Dst, c)
+}
+
+// isEnd tells if the rune vi is the last rune of a k-mer.
+func (g *DBG) isEnd(vi int) bool {
+	if g.starts == nil {
+		return vi%g.K == g.K-1
+	}
+	c := sort.SearchInts(g.starts, vi+1) - 1
+	return vi-g.starts[c] >= g.K-1
 }
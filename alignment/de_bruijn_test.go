@@ -8,7 +8,6 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
-	"strings"
 	"testing"
 	"time"
 
@@ -119,26 +118,15 @@ func TestNDeBruijn(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	sep := strings.Repeat("-", k)
-	allSeqs := ""
+	b := debruijn.NewBuilder(k + 1)
 	for _, fname := range fnames {
 		seq, err := readSequence(fname)
 		if err != nil {
 			t.Fatal(err)
 		}
-		allSeqs = allSeqs + seq + sep
+		b.Add([]rune(seq))
 	}
-	bg := debruijn.NewDeBruijn([]rune(allSeqs), k+1)
-	newEdgs := make([][2]int, 0, len(bg.Edges))
-	for _, e := range bg.Edges {
-		p0 := strings.Index(string(bg.Vertices[e[0]]), "-")
-		p1 := strings.Index(string(bg.Vertices[e[1]]), "-")
-		if p0 >= 0 || p1 >= 0 {
-			continue
-		}
-		newEdgs = append(newEdgs, e)
-	}
-	bg.Edges = newEdgs
+	bg := b.Graph()
 
 	ppg := bg.Parse()
 	pg := &parse.Graph{Nodes: ppg.Vertices, Edges: ppg.Edges}
@@ -485,3 +473,57 @@ func cmpStr(ss1, ss2 string) (string, string) {
 	}
 	return string(t1), string(t2)
 }
+
+func TestUnitigDBG(t *testing.T) {
+	const k = 7
+	r := rand.New(rand.NewSource(4))
+	ref := randomSeq(r, 600)
+	for _, d := range []*debruijn.DeBruijn{
+		debruijn.NewDeBruijn([]rune(ref+ref[100:300]), k+1),
+		debruijn.NewCanonicalDeBruijn([]rune(ref), k+1),
+	} {
+		p := d.Parse()
+		pg := &parse.Graph{Nodes: p.Vertices, Edges: p.Edges}
+		u := d.ParseUnitigs()
+		ug := &parse.Graph{Nodes: u.Vertices, Edges: u.Edges}
+		if 2*len(ug.Nodes) > len(pg.Nodes) {
+			t.Fatalf("unitigs not compacted: %d, parse: %d", len(ug.Nodes), len(pg.Nodes))
+		}
+		for i := 0; i < 5; i++ {
+			start := r.Intn(len(ref) - 100)
+			seq := putErrs(ref[start:start+100], 0.1)
+			_, want := NewDBG(NewBase(pg, seq, weight), d.K).Graph().ShortestPath()
+			g := NewUnitigDBG(NewBase(ug, seq, weight), d.K, u.Starts()).Graph()
+			path, got := g.ShortestPath()
+			if got != want {
+				t.Fatalf("invalid dist want: %d, got: %d", want, got)
+			}
+			if c := pathCost(g, path); c != got {
+				t.Fatalf("invalid path cost want: %d, got: %d", got, c)
+			}
+		}
+	}
+}
+
+func TestDBGEndGaps(t *testing.T) {
+	const k = 7
+	r := rand.New(rand.NewSource(5))
+	ref := randomSeq(r, 300)
+	p := debruijn.NewDeBruijn([]rune(ref), k+1).Parse()
+	pg := &parse.Graph{Nodes: p.Vertices, Edges: p.Edges}
+	seq := "TTTTTTTTTT" + ref[100:200] + "TTTTTTTTTT"
+
+	g := NewDBG(NewBase(pg, seq, mismatch2), k).Graph()
+	_, glocal := g.ShortestPath()
+	g = NewDBG(NewBase(pg, seq, mismatch2, WithEndGaps(LocalEnds), WithClip(1)), k).Graph()
+	path, local := g.ShortestPath()
+	if local > 20 || local >= glocal {
+		t.Fatalf("invalid local distance: %d, glocal: %d", local, glocal)
+	}
+	if c := pathCost(g, path); c != local {
+		t.Fatalf("invalid path cost want: %d, got: %d", local, c)
+	}
+	if start, end := g.Clipped(path); start > 10 || end < 110 {
+		t.Fatalf("invalid clip: [%d, %d)", start, end)
+	}
+}
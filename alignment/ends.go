@@ -0,0 +1,252 @@
+package alignment
+
+// EndGaps tells which ends of the sequence and of the graph
+// may be left out of the alignment without being aligned
+// to spaces.
+type EndGaps uint8
+
+const (
+	// SeqStart lets the alignment start after the
+	// first rune of the sequence.
+	SeqStart EndGaps = 1 << iota
+	// SeqEnd lets the alignment end before the
+	// last rune of the sequence.
+	SeqEnd
+	// GraphStart lets the alignment start at any vertex,
+	// not only at the ones without in edges.
+	GraphStart
+	// GraphEnd lets the alignment end at any vertex,
+	// not only at the ones without out edges.
+	GraphEnd
+	// Unanchored lets the alignment start and end in the
+	// middle of both the sequence and the graph. Without
+	// it each end of the alignment must touch the end of
+	// the sequence or the end of the graph.
+	Unanchored
+)
+
+const (
+	// Global aligns the whole sequence to a path from a
+	// graph start to a graph end. The vertices of the path
+	// before the first aligned rune and after the last one
+	// are charged as spaces, they are not in the shortest
+	// path but they are deletions of its Alignment.
+	Global EndGaps = 0
+	// Glocal aligns the whole sequence to any path
+	// of the graph.
+	Glocal = GraphStart | GraphEnd
+	// Overlap aligns a suffix of the sequence to a prefix
+	// of a graph path, or the other way around.
+	Overlap = SeqStart | SeqEnd | GraphStart | GraphEnd
+	// LocalEnds aligns any piece of the sequence to
+	// any path of the graph.
+	LocalEnds = Overlap | Unanchored
+)
+
+type Option func(g *Base)
+
+// WithEndGaps sets the ends of the sequence and of the
+// graph that may be left out of the alignment.
+func WithEndGaps(e EndGaps) Option {
+	return func(g *Base) {
+		g.EndGaps = e
+	}
+}
+
+// WithClip sets the cost of each rune of the sequence left out
+// of the alignment. It should be positive with Overlap and LocalEnds,
+// or a single matching rune would be the best alignment.
+func WithClip(c int64) Option {
+	return func(g *Base) {
+		g.Clip = c
+	}
+}
+
+// startCost returns the cost of starting the alignment aligning
+// the rune of row to the vertex v, ok is false if it may not
+// start there. When the graph start is not free the runes of the
+// graph before v are aligned to spaces.
+func (g *Base) startCost(row, v int) (c int64, ok bool) {
+	if row > 0 && g.EndGaps&SeqStart == 0 {
+		return 0, false
+	}
+	c = int64(row) * g.Clip
+	d := g.startGap(row, v)
+	if d < 0 {
+		return 0, false
+	}
+	return c + int64(d)*g.Score(space, 'A'), true
+}
+
+// startGap returns the number of vertices of the graph before v
+// aligned to spaces when the alignment starts aligning the rune of
+// row to v, -1 if the start of the graph cannot be reached.
+func (g *Base) startGap(row, v int) int {
+	if g.EndGaps&GraphStart != 0 && (row == 0 || g.EndGaps&Unanchored != 0) {
+		return 0
+	}
+	return g.vertexDepth(v)
+}
+
+// endCost returns the cost of ending the alignment after aligning
+// the rune of row to the vertex v, ok is false if it may not end
+// there. When the graph end is not free the runes of the graph
+// after v are aligned to spaces.
+func (g *Base) endCost(row, v int) (c int64, ok bool) {
+	last := len(g.SeqLabels) - 1
+	if row < last && g.EndGaps&SeqEnd == 0 {
+		return 0, false
+	}
+	c = int64(last-row) * g.Clip
+	h := g.endGap(row, v)
+	if h < 0 {
+		return 0, false
+	}
+	return c + int64(h)*g.Score(space, 'A'), true
+}
+
+// endGap returns the number of vertices of the graph after v
+// aligned to spaces when the alignment ends aligning the rune of
+// row to v, -1 if the end of the graph cannot be reached.
+func (g *Base) endGap(row, v int) int {
+	last := len(g.SeqLabels) - 1
+	if g.EndGaps&GraphEnd != 0 && (row == last || g.EndGaps&Unanchored != 0) {
+		return 0
+	}
+	return g.vertexHeight(v)
+}
+
+// graphGapper is implemented by the Interfaces that align the
+// vertices of the graph before the start and after the end of
+// the alignment to spaces, out of the shortest path.
+type graphGapper interface {
+	startGap(row, v int) int
+	endGap(row, v int) int
+	vertexDepth(v int) int
+	vertexHeight(v int) int
+}
+
+// graphGaps returns the vertices of the graph aligned to spaces
+// before the first vertex of path and after the last one, a
+// shortest chain from a vertex without in edges and one to a
+// vertex without out edges.
+func (g *Graph) graphGaps(path []int) (before, after []int) {
+	gg, ok := g.Interface.(graphGapper)
+	if !ok {
+		return nil, nil
+	}
+	vertices := len(g.Labels)
+	first, last := path[1], path[len(path)-2]
+	n := gg.startGap(first/vertices, first%vertices)
+	m := gg.endGap(last/vertices, last%vertices)
+	if n <= 0 && m <= 0 {
+		return nil, nil
+	}
+	in := make([][]int, vertices)
+	out := make([][]int, vertices)
+	for u := 0; u < vertices; u++ {
+		g.successors(u, func(w int) {
+			if w != u {
+				out[u] = append(out[u], w)
+				in[w] = append(in[w], u)
+			}
+		})
+	}
+	before = gapChain(first%vertices, n, in, gg.vertexDepth)
+	after = gapChain(last%vertices, m, out, gg.vertexHeight)
+	// The chain before is found backward.
+	for i, j := 0, len(before)-1; i < j; i, j = i+1, j-1 {
+		before[i], before[j] = before[j], before[i]
+	}
+	return before, after
+}
+
+// gapChain returns the n vertices following next from v, each
+// one step closer to the boundary of the graph by dist.
+func gapChain(v, n int, next [][]int, dist func(v int) int) []int {
+	chain := make([]int, 0, n)
+	for len(chain) < n {
+		found := false
+		for _, u := range next[v] {
+			if dist(u) == dist(v)-1 {
+				v, found = u, true
+				break
+			}
+		}
+		if !found {
+			break
+		}
+		chain = append(chain, v)
+	}
+	return chain
+}
+
+func (g *Base) vertexDepth(v int) int {
+	if g.nodes != nil {
+		return g.nodes.vertexDepth(v)
+	}
+	return g.depth[v]
+}
+
+func (g *Base) vertexHeight(v int) int {
+	if g.nodes != nil {
+		return g.nodes.vertexHeight(v)
+	}
+	return g.height[v]
+}
+
+func (g *Base) maxDepth() int {
+	if g.nodes != nil {
+		return g.nodes.maxDepth()
+	}
+	return maxInt(g.depth)
+}
+
+func (g *Base) maxHeight() int {
+	if g.nodes != nil {
+		return g.nodes.maxHeight()
+	}
+	return maxInt(g.height)
+}
+
+// boundaryDist returns, for each vertex, the least number of
+// vertices between it and a vertex without in edges, depth, and
+// between it and a vertex without out edges, height. It is -1 when
+// there is no such vertex. Loops are ignored.
+func boundaryDist(es [][2]int, vertices int) (depth, height []int) {
+	in := make([][]int, vertices)
+	out := make([][]int, vertices)
+	for _, e := range es {
+		if e[0] == e[1] {
+			continue
+		}
+		out[e[0]] = append(out[e[0]], e[1])
+		in[e[1]] = append(in[e[1]], e[0])
+	}
+	return bfs(in, out), bfs(out, in)
+}
+
+// bfs returns the distance of each vertex from the vertices
+// without edges in back, following the edges in forward.
+func bfs(back, forward [][]int) []int {
+	dist := make([]int, len(back))
+	queue := make([]int, 0, len(back))
+	for v := range dist {
+		dist[v] = -1
+		if len(back[v]) == 0 {
+			dist[v] = 0
+			queue = append(queue, v)
+		}
+	}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, w := range forward[v] {
+			if dist[w] < 0 {
+				dist[w] = dist[v] + 1
+				queue = append(queue, w)
+			}
+		}
+	}
+	return dist
+}
@@ -0,0 +1,80 @@
+package alignment
+
+import "testing"
+
+func mismatch2(a, b rune) int64 {
+	if a == b {
+		return 0
+	}
+	return 2
+}
+
+func TestEndGaps(t *testing.T) {
+	tests := []struct {
+		name  string
+		ends  EndGaps
+		clip  int64
+		seq   string
+		dist  int64
+		start int
+		end   int
+	}{
+		{name: "global", ends: Global, seq: "ACGTACGT", dist: 8, start: 0, end: 8},
+		{name: "glocal", ends: Glocal, seq: "ACGTACGT", dist: 0, start: 0, end: 8},
+		{name: "overlap", ends: Overlap, clip: 1, seq: "ACGTGGTTT", dist: 3, start: 0, end: 6},
+		{name: "overlap prefix", ends: Overlap, clip: 1, seq: "TTTGGACG", dist: 3, start: 3, end: 8},
+		{name: "glocal suffix", ends: Glocal, seq: "ACGTGGTTT", dist: 6, start: 0, end: 9},
+	}
+	pg := chain("GGACGTACGTGG")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewBase(pg, tt.seq, mismatch2, WithEndGaps(tt.ends), WithClip(tt.clip)).Graph()
+			path, dist := g.ShortestPath()
+			if dist != tt.dist {
+				t.Fatalf("invalid distance want: %d, got: %d", tt.dist, dist)
+			}
+			start, end := g.Clipped(path)
+			if start != tt.start || end != tt.end {
+				t.Fatalf("invalid clip want: [%d, %d), got: [%d, %d)",
+					tt.start, tt.end, start, end)
+			}
+		})
+	}
+}
+
+func TestGlobalDeletions(t *testing.T) {
+	pg := chain("GGACGTACGTGG")
+	g := NewBase(pg, "ACGTACGT", mismatch2, WithEndGaps(Global)).Graph()
+	path, dist := g.ShortestPath()
+	a := g.Alignment(path, dist)
+	if a.Score != 8 {
+		t.Fatalf("invalid distance want: %d, got: %d", 8, a.Score)
+	}
+	if cigar := a.Cigar(); cigar != "2D8=2D" {
+		t.Fatalf("invalid cigar want: %s, got: %s", "2D8=2D", cigar)
+	}
+	if len(a.Nodes) != 12 || a.Nodes[0] != 0 || a.Nodes[11] != 11 {
+		t.Fatalf("invalid nodes: %v", a.Nodes)
+	}
+	s1, t1 := a.Strings()
+	if s1 != "GGACGTACGTGG" || t1 != "--ACGTACGT--" {
+		t.Fatalf("invalid alignment: got %s\n%s", s1, t1)
+	}
+}
+
+func TestLocalEnds(t *testing.T) {
+	pg := chain("GGACGTACGTGG")
+	g := NewBase(pg, "TTACGTACGTTTT", mismatch2,
+		WithEndGaps(LocalEnds), WithClip(1)).Graph()
+	path, dist := g.ShortestPath()
+	if dist != 5 {
+		t.Fatalf("invalid distance want: %d, got: %d", 5, dist)
+	}
+	if start, end := g.Clipped(path); start != 2 || end != 10 {
+		t.Fatalf("invalid clip want: [2, 10), got: [%d, %d)", start, end)
+	}
+	s1, t1 := g.Align(path)
+	if s1 != "ACGTACGT" || t1 != "ACGTACGT" {
+		t.Fatalf("invalid alignment: got %s\n%s", s1, t1)
+	}
+}
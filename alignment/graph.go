@@ -2,6 +2,7 @@ package alignment
 
 import (
 	"github.com/rschio/align/alignment/internal/bitbucket"
+	"github.com/rschio/align/alignment/internal/dial"
 	"github.com/rschio/graph"
 )
 
@@ -13,7 +14,16 @@ type Graph struct {
 	SeqLabels []rune
 	Loops     []bool
 	Score     ScoreFn
-	order     int
+	// Band, if not nil, restricts the alignment to
+	// a band of vertices of each row.
+	Band  *Band
+	order int
+	// labelSet are the distinct runes of Labels, shared by
+	// the graphs of the same Base.
+	labelSet []rune
+	// nodes, if not nil, are the edges of a graph of
+	// nodes labeled by strings, instead of Edges.
+	nodes *nodes
 }
 
 // Assert, in compile time, Graph satisfies
@@ -23,11 +33,62 @@ var _ graph.Iterator = (*Graph)(nil)
 type Interface interface {
 	VisitFromSrc(do func(w int, c int64) bool) bool
 	VisitFromLastRow(v int, do func(w int, c int64) bool) bool
+	// VisitFromRow visits the edges leaving v, a vertex
+	// before the last row, that are not part of the
+	// alignment grid.
+	VisitFromRow(v int, do func(w int, c int64) bool) bool
+	// MaxEndCost returns an upper bound of the costs the edges
+	// leaving Src and reaching Dst add to the score.
+	MaxEndCost() int64
 }
 
 func (g *Graph) ShortestPath() (path []int, dist int64) {
-	q := new(bitbucket.Queue)
-	return graph.ShortestPathWithQueue(g, q, g.Src, g.Dst)
+	return new(Dijkstra).ShortestPath(g)
+}
+
+// MaxCost returns an upper bound of the edge costs, from
+// the range of g.Score over the runes of the graph and
+// of the sequence.
+func (g *Graph) MaxCost() int64 {
+	labels := g.labelSet
+	if labels == nil {
+		labels = runeSet(g.Labels)
+	}
+	max := g.Score('A', space)
+	if c := g.Score(space, 'A'); c > max {
+		max = c
+	}
+	for _, a := range runeSet(g.SeqLabels) {
+		for _, b := range labels {
+			if c := g.Score(a, b); c > max {
+				max = c
+			}
+		}
+	}
+	return max + g.MaxEndCost()
+}
+
+// runeSet returns the distinct runes of rs.
+func runeSet(rs []rune) []rune {
+	seen := make(map[rune]struct{})
+	var set []rune
+	for _, r := range rs {
+		if _, ok := seen[r]; !ok {
+			seen[r] = struct{}{}
+			set = append(set, r)
+		}
+	}
+	return set
+}
+
+// newQueue returns the queue for edge costs in the range
+// [0, maxCost]. bitbucket.Queue is faster but it only
+// works with costs 0 and 1.
+func newQueue(maxCost int64) graph.DistQueue {
+	if maxCost <= 1 {
+		return new(bitbucket.Queue)
+	}
+	return dial.New(maxCost)
 }
 
 func (g *Graph) Order() int {
@@ -35,6 +96,13 @@ func (g *Graph) Order() int {
 }
 
 func (g *Graph) Visit(v int, do func(w int, c int64) bool) bool {
+	if g.Band != nil {
+		return g.visitBand(v, do)
+	}
+	return g.visit(v, do)
+}
+
+func (g *Graph) visit(v int, do func(w int, c int64) bool) bool {
 	if !g.normalRow(v) {
 		if v == g.Dst {
 			return false
@@ -44,6 +112,9 @@ func (g *Graph) Visit(v int, do func(w int, c int64) bool) bool {
 		}
 		return g.VisitFromLastRow(v, do)
 	}
+	if g.nodes != nil {
+		return g.visitNodes(v, do)
+	}
 	vertices := len(g.Labels)
 	vi := v % vertices
 	row := v / vertices
@@ -59,9 +130,9 @@ func (g *Graph) Visit(v int, do func(w int, c int64) bool) bool {
 		if w == vertical {
 			break
 		}
-		// g.Score('A', space) always have a
+		// g.Score(space, 'A') always have a
 		// positive cost.
-		c := g.Score('A', space)
+		c := g.Score(space, 'A')
 		if do(w, c) {
 			return true
 		}
@@ -85,7 +156,7 @@ func (g *Graph) Visit(v int, do func(w int, c int64) bool) bool {
 	// neighbor and the only edge that exists
 	// is the vertical one already treat above.
 	if i >= len(g.Edges[vi]) {
-		return false
+		return g.VisitFromRow(v, do)
 	}
 	// Process the diagonal edges.
 	diagonals := g.Edges[vi][i:]
@@ -97,7 +168,7 @@ func (g *Graph) Visit(v int, do func(w int, c int64) bool) bool {
 			return true
 		}
 	}
-	return false
+	return g.VisitFromRow(v, do)
 }
 
 func (g *Graph) normalRow(v int) bool {
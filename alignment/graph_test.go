@@ -105,3 +105,31 @@ func TestGaph(t *testing.T) {
 	}
 
 }
+
+func TestShortestPathCosts(t *testing.T) {
+	score := func(a, b rune) int64 {
+		switch {
+		case a == b:
+			return 0
+		case a == space || b == space:
+			return 6
+		}
+		return 4
+	}
+	seqfile := filepath.Join("testdata", "benchdata", "sequence_data", "seq_100.txt")
+	seq, err := readSequence(seqfile)
+	if err != nil {
+		t.Fatalf("failed to read seqfile %s: %v", seqfile, err)
+	}
+	graphfile := filepath.Join("testdata", "benchdata", "graph_data", "graph_1000v_4d.txt")
+	pg, err := readSeqGraph(graphfile)
+	if err != nil {
+		t.Fatalf("failed to read graphfile %s: %v", graphfile, err)
+	}
+	g := NewBase(pg, seq, score).Graph()
+	_, want := graph.ShortestPath(g, g.Src, g.Dst)
+	_, got := g.ShortestPath()
+	if got != want {
+		t.Fatalf("invalid distance want: %d, got: %d", want, got)
+	}
+}
@@ -14,6 +14,8 @@ func (q *Queue) SetDist(cost []int64) {
 		*q = Queue{}
 	}
 	q.cost = cost
+	q.offset = 0
+	q.length = 0
 	for i := range q.buckets {
 		q.buckets[i] = bit.New()
 	}
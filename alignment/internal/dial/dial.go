@@ -0,0 +1,80 @@
+// Package dial implements Dial's queue, a circular array of buckets
+// for the shortest path algorithm when the edge costs are integers
+// in the range [0, C]. Every cost in the queue is in the range
+// [min, min+C], so C+1 buckets are enough to hold them.
+package dial
+
+type Queue struct {
+	index   []int
+	cost    []int64
+	buckets [][]int
+	// offset is the cost of the current bucket.
+	offset int64
+	length int
+}
+
+// New returns a queue for edge costs in the range [0, maxCost].
+func New(maxCost int64) *Queue {
+	return &Queue{buckets: make([][]int, maxCost+1)}
+}
+
+func (q *Queue) SetDist(cost []int64) {
+	if cap(q.index) < len(cost) {
+		q.index = make([]int, len(cost))
+	}
+	q.index = q.index[:len(cost)]
+	q.cost = cost
+	q.offset = 0
+	q.length = 0
+	for i := range q.buckets {
+		q.buckets[i] = q.buckets[i][:0]
+	}
+}
+
+func (q *Queue) Len() int { return q.length }
+
+func (q *Queue) Fix(v int, cost int64) {
+	q.PopV(v)
+	q.Push(v, cost)
+}
+
+func (q *Queue) Pop() int {
+	p := q.bucket(q.offset)
+	for len(q.buckets[p]) == 0 {
+		q.offset++
+		p++
+		if p == len(q.buckets) {
+			p = 0
+		}
+	}
+	b := q.buckets[p]
+	n := len(b)
+	v := b[n-1]
+	q.buckets[p] = b[0 : n-1]
+	q.length--
+	return v
+}
+
+func (q *Queue) Push(v int, cost int64) {
+	p := q.bucket(cost)
+	b := q.buckets[p]
+	q.index[v] = len(b)
+	q.buckets[p] = append(b, v)
+	q.cost[v] = cost
+	q.length++
+}
+
+func (q *Queue) PopV(v int) {
+	p := q.bucket(q.cost[v])
+	i := q.index[v]
+	b := q.buckets[p]
+	n := len(b)
+	b[i] = b[n-1]
+	q.index[b[i]] = i
+	q.buckets[p] = b[0 : n-1]
+	q.length--
+}
+
+func (q *Queue) bucket(cost int64) int {
+	return int(cost % int64(len(q.buckets)))
+}
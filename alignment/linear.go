@@ -0,0 +1,341 @@
+package alignment
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+)
+
+// srcRowVisitor is implemented by the Interfaces that visit
+// the edges leaving Src to a single row.
+type srcRowVisitor interface {
+	VisitFromSrcRow(row int, do func(w int, c int64) bool) bool
+}
+
+// visitFromSrcRow visits the edges leaving Src to the vertices
+// of row, filtering every edge of Src when the Interface
+// cannot visit a single row.
+func (g *Graph) visitFromSrcRow(row int, do func(w int, c int64) bool) bool {
+	if g.Band != nil {
+		do = g.Band.filter(len(g.Labels), g.Dst, do)
+	}
+	if r, ok := g.Interface.(srcRowVisitor); ok {
+		return r.VisitFromSrcRow(row, do)
+	}
+	lo, hi := row*len(g.Labels), (row+1)*len(g.Labels)
+	return g.VisitFromSrc(func(w int, c int64) bool {
+		if w >= lo && w < hi {
+			return do(w, c)
+		}
+		return false
+	})
+}
+
+// Linear is a Searcher whose memory grows with the square root
+// of the sequence length, instead of with the order of the graph.
+//
+// The rows of the alignment grid are searched one at a time, as
+// every edge leaves a row to the same row or to the next one, and
+// the distances of a row out of each block of rows are kept as
+// checkpoints. The path is traced back from Dst recomputing, from
+// its checkpoint, the parents of one block at a time. Each vertex
+// is searched at most twice.
+type Linear struct {
+	// MaxMemory is the memory ceiling, in bytes, of a search,
+	// 0 is no ceiling. The blocks are as large as fit in it,
+	// and the least memory is used without a ceiling.
+	MaxMemory int64
+	err       error
+}
+
+// NewLinear returns a Linear with the memory ceiling maxMemory.
+func NewLinear(maxMemory int64) *Linear {
+	return &Linear{MaxMemory: maxMemory}
+}
+
+// ShortestPath returns the path of Search. The path is empty
+// and dist is -1 when the search fails, the error is returned
+// by Err.
+func (l *Linear) ShortestPath(g *Graph) (path []int, dist int64) {
+	path, dist, l.err = l.Search(g)
+	if l.err != nil {
+		return []int{}, -1
+	}
+	return path, dist
+}
+
+// Err returns the error of the last ShortestPath.
+func (l *Linear) Err() error {
+	return l.err
+}
+
+// Search returns the shortest path from Src to Dst, it returns an
+// error if even the layout of least memory needs more memory than
+// l.MaxMemory.
+func (l *Linear) Search(g *Graph) (path []int, dist int64, err error) {
+	rows, vertices := len(g.SeqLabels), len(g.Labels)
+	if rows == 0 || vertices == 0 {
+		return []int{}, -1, nil
+	}
+	block, err := l.layout(rows, vertices)
+	if err != nil {
+		return nil, -1, err
+	}
+	blocks := (rows + block - 1) / block
+
+	s := newRowSearch(g)
+	checkpoints := make([][]int64, blocks)
+	for row := 0; row < rows; row++ {
+		s.search(row, nil)
+		if (row+1)%block == 0 && row+1 < rows {
+			checkpoints[(row+1)/block] = append([]int64(nil), s.dist...)
+		}
+		s.advance()
+	}
+	if s.end == -1 {
+		return []int{}, -1, nil
+	}
+
+	// Trace back the path from Dst, recomputing
+	// the parents of each block it crosses.
+	parents := make([][]int, block)
+	for i := range parents {
+		parents[i] = make([]int, vertices)
+	}
+	path = []int{g.Dst}
+	first := -1
+	for v := s.end; v != g.Src; {
+		path = append(path, v)
+		row := v / vertices
+		if b := row / block; first != b*block {
+			first = b * block
+			s.recompute(first, min(first+block, rows), checkpoints[b], parents)
+		}
+		v = parents[row-first][v%vertices]
+	}
+	path = append(path, g.Src)
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, s.endDist, nil
+}
+
+// layout returns the number of rows of a block, the rows between
+// checkpoints and the rows whose parents are kept at once. Without
+// a ceiling it is the block of least memory, about the square root
+// of rows. Otherwise it is the largest block that fits in
+// l.MaxMemory, as larger blocks are recomputed in fewer pieces.
+func (l *Linear) layout(rows, vertices int) (int, error) {
+	least := int(math.Ceil(math.Sqrt(float64(rows))))
+	for b := 1; b <= rows; b++ {
+		if linearMemory(rows, vertices, b) < linearMemory(rows, vertices, least) {
+			least = b
+		}
+	}
+	if l.MaxMemory <= 0 {
+		return least, nil
+	}
+	if need := linearMemory(rows, vertices, least); need > l.MaxMemory {
+		return 0, fmt.Errorf("invalid memory ceiling: %d bytes, the search needs: %d",
+			l.MaxMemory, need)
+	}
+	// The memory grows with the blocks larger than least,
+	// lo is always a block that fits.
+	lo, hi := least, rows
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if linearMemory(rows, vertices, mid) <= l.MaxMemory {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	return lo, nil
+}
+
+// linearMemory returns the bytes used by the search of
+// rows in blocks of block rows.
+func linearMemory(rows, vertices, block int) int64 {
+	const word = 8
+	blocks := (rows + block - 1) / block
+	checkpoints := int64(blocks-1) * int64(vertices) * word
+	parents := int64(block) * int64(vertices) * word
+	// The distances of two rows, the parents of the next
+	// row, the done flags and the queue.
+	search := int64(vertices) * (4*word + 1 + 2*word)
+	return checkpoints + parents + search
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// rowSearch is the Dijkstra's search of a row of the alignment
+// grid, started from the distances of the edges reaching it.
+type rowSearch struct {
+	g        *Graph
+	vertices int
+	row      int
+	// dist are the distances of the row and next the ones
+	// of the next row, from the edges leaving the row.
+	dist, next []int64
+	// parent and nextParent are the parents of the row and
+	// of the next row, nil when they are not kept.
+	parent, nextParent []int
+	done               []bool
+	q                  distQueue
+	// end is the vertex before Dst in the shortest path
+	// found, -1 if none.
+	end     int
+	endDist int64
+	v       int
+}
+
+func newRowSearch(g *Graph) *rowSearch {
+	n := len(g.Labels)
+	s := &rowSearch{
+		g:          g,
+		vertices:   n,
+		dist:       make([]int64, n),
+		next:       make([]int64, n),
+		nextParent: make([]int, n),
+		done:       make([]bool, n),
+		end:        -1,
+	}
+	for i := range s.dist {
+		s.dist[i], s.next[i] = -1, -1
+	}
+	return s
+}
+
+// search finds the distances of row, the parents are
+// stored in parent if not nil.
+func (s *rowSearch) search(row int, parent []int) {
+	s.row = row
+	s.parent = parent
+	offset := row * s.vertices
+	for vi, d := range s.dist {
+		s.done[vi] = false
+		if parent != nil {
+			parent[vi] = s.nextParent[vi]
+		}
+		if d != -1 {
+			heap.Push(&s.q, distItem{vi, d})
+		}
+	}
+	s.v = s.g.Src
+	s.g.visitFromSrcRow(row, s.relax)
+	visit := s.g.visitor(s.relax)
+	for s.q.Len() > 0 {
+		it := heap.Pop(&s.q).(distItem)
+		if s.done[it.v] || it.d > s.dist[it.v] {
+			continue
+		}
+		s.done[it.v] = true
+		s.v = offset + it.v
+		visit(s.v)
+	}
+}
+
+// advance moves the search to the next row.
+func (s *rowSearch) advance() {
+	s.dist, s.next = s.next, s.dist
+	for i := range s.next {
+		s.next[i] = -1
+	}
+}
+
+// recompute finds the parents of the rows [first, last), the
+// distances of the row before first are in prev.
+func (s *rowSearch) recompute(first, last int, prev []int64, parents [][]int) {
+	for i := range s.dist {
+		s.dist[i], s.next[i] = -1, -1
+	}
+	if first > 0 {
+		// Relax only the edges leaving the
+		// row before to the first row.
+		s.row = first - 1
+		s.parent = nil
+		offset := s.row * s.vertices
+		lo, hi := first*s.vertices, (first+1)*s.vertices
+		visit := s.g.visitor(func(w int, c int64) bool {
+			if w >= lo && w < hi {
+				s.relax(w, c)
+			}
+			return false
+		})
+		for vi, d := range prev {
+			if d == -1 {
+				continue
+			}
+			s.dist[vi] = d
+			s.v = offset + vi
+			visit(s.v)
+		}
+		s.advance()
+	}
+	for row := first; row < last; row++ {
+		s.search(row, parents[row-first])
+		s.advance()
+	}
+}
+
+func (s *rowSearch) relax(w int, c int64) bool {
+	if c < 0 {
+		return false
+	}
+	var d int64
+	if s.v != s.g.Src {
+		d = s.dist[s.v%s.vertices]
+	}
+	alt := d + c
+	offset := s.row * s.vertices
+	switch {
+	case w == s.g.Dst:
+		if s.end == -1 || alt < s.endDist {
+			s.end, s.endDist = s.v, alt
+		}
+	case w < offset+s.vertices:
+		// An edge to the same row, or from Src.
+		wi := w - offset
+		if s.dist[wi] == -1 || alt < s.dist[wi] {
+			s.dist[wi] = alt
+			if s.parent != nil {
+				s.parent[wi] = s.v
+			}
+			heap.Push(&s.q, distItem{wi, alt})
+		}
+	default:
+		wi := w - offset - s.vertices
+		if s.next[wi] == -1 || alt < s.next[wi] {
+			s.next[wi] = alt
+			s.nextParent[wi] = s.v
+		}
+	}
+	return false
+}
+
+type distItem struct {
+	v int
+	d int64
+}
+
+// distQueue is a binary heap of vertices by distance, for the
+// searches whose priorities are not bounded by the maximum cost
+// of an edge, where the bucket queues don't fit.
+type distQueue []distItem
+
+func (q distQueue) Len() int            { return len(q) }
+func (q distQueue) Less(i, j int) bool  { return q[i].d < q[j].d }
+func (q distQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *distQueue) Push(x interface{}) { *q = append(*q, x.(distItem)) }
+func (q *distQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	it := old[n-1]
+	*q = old[:n-1]
+	return it
+}
@@ -0,0 +1,108 @@
+package alignment
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/rschio/align/parse"
+)
+
+// pathCost returns the cost of path, taking the cheapest
+// edge between each pair of vertices, or -1 if an edge
+// of path is not in g.
+func pathCost(g *Graph, path []int) int64 {
+	var sum int64
+	for i := 1; i < len(path); i++ {
+		best := int64(-1)
+		g.Visit(path[i-1], func(w int, c int64) bool {
+			if w == path[i] && (best == -1 || c < best) {
+				best = c
+			}
+			return false
+		})
+		if best == -1 {
+			return -1
+		}
+		sum += best
+	}
+	return sum
+}
+
+func TestLinear(t *testing.T) {
+	seqfile := filepath.Join("testdata", "benchdata", "sequence_data", "seq_100.txt")
+	seq, err := readSequence(seqfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	graphfile := filepath.Join("testdata", "benchdata", "graph_data", "graph_1000v_4d.txt")
+	pg, err := readSeqGraph(graphfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seqs := []string{seq, seq[:40] + "TTTT" + seq[50:], seq[7:9], "GATTACA"}
+	opts := []Option{
+		WithEndGaps(Global),
+		WithEndGaps(Glocal),
+		WithEndGaps(Overlap),
+		WithEndGaps(LocalEnds),
+	}
+	// The test graph has no vertex without in edges,
+	// the chain can be aligned globally.
+	for _, pg := range []*parse.Graph{pg, chain("GGACGTACGTGG")} {
+		for _, score := range []ScoreFn{weight, mismatch2} {
+			for i, opt := range opts {
+				for _, s := range seqs {
+					g := NewBase(pg, s, score, opt, WithClip(1)).Graph()
+					_, want := g.ShortestPath()
+					path, dist, err := NewLinear(0).Search(g)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if dist != want {
+						t.Fatalf("%d %q: invalid distance want: %d, got: %d", i, s, want, dist)
+					}
+					if c := pathCost(g, path); dist != -1 && c != dist {
+						t.Fatalf("%d %q: invalid path cost want: %d, got: %d", i, s, dist, c)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestLinearMaxMemory(t *testing.T) {
+	pg := chain("GGACGTACGTGG")
+	g := NewBase(pg, "ACGTACGT", weight).Graph()
+	l := NewLinear(64)
+	if _, _, err := l.Search(g); err == nil {
+		t.Fatal("want error, got nil")
+	}
+	if path, dist := l.ShortestPath(g); len(path) != 0 || dist != -1 || l.Err() == nil {
+		t.Fatalf("want failed search, got dist: %d, err: %v", dist, l.Err())
+	}
+
+	// The blocks grow to fill the ceiling, and the
+	// layout of least memory is kept without one.
+	rows, vertices := len(g.SeqLabels), len(g.Labels)
+	least, err := NewLinear(0).layout(rows, vertices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, max := range []int64{linearMemory(rows, vertices, least), 1 << 20} {
+		l := NewLinear(max)
+		block, err := l.layout(rows, vertices)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if need := linearMemory(rows, vertices, block); need > max {
+			t.Fatalf("layout over the ceiling: %d > %d", need, max)
+		}
+		if max == 1<<20 && block != rows {
+			t.Fatalf("invalid block want: %d, got: %d", rows, block)
+		}
+		_, want := g.ShortestPath()
+		if _, dist, err := l.Search(g); err != nil || dist != want {
+			t.Fatalf("invalid search want: %d, got: %d, err: %v", want, dist, err)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package alignment
+
+// Local is a local alignment, it may start and end at any rune
+// of the sequence and at any vertex of the graph. Each rune of
+// the sequence left out of the alignment costs Clip, so Clip
+// should be positive or a single matching rune would be the
+// best alignment. It is the same as the options
+// WithEndGaps(LocalEnds) and WithClip(Clip).
+type Local struct {
+	*Base
+	Clip int64
+}
+
+func NewLocal(g *Base, clip int64) *Local {
+	return &Local{
+		Base: g,
+		Clip: clip,
+	}
+}
+
+func (g *Local) Graph() *Graph {
+	g.Base.EndGaps = LocalEnds
+	g.Base.Clip = g.Clip
+	return g.Base.Graph()
+}
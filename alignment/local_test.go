@@ -0,0 +1,29 @@
+package alignment
+
+import (
+	"testing"
+
+	"github.com/rschio/graph"
+)
+
+func TestLocal(t *testing.T) {
+	score := func(a, b rune) int64 {
+		if a == b {
+			return 0
+		}
+		return 2
+	}
+	pg := chain("GGACGTACGTGG")
+	g := NewLocal(NewBase(pg, "TTACGTACGTTTT", score), 1).Graph()
+	path, dist := graph.ShortestPath(g, g.Src, g.Dst)
+	if dist != 5 {
+		t.Fatalf("invalid distance want: %d, got: %d", 5, dist)
+	}
+	if start, end := g.Clipped(path); start != 2 || end != 10 {
+		t.Fatalf("invalid clip want: [2, 10), got: [%d, %d)", start, end)
+	}
+	s1, t1 := g.Align(path)
+	if s1 != "ACGTACGT" || t1 != "ACGTACGT" {
+		t.Fatalf("invalid alignment: got %s\n%s", s1, t1)
+	}
+}
@@ -0,0 +1,316 @@
+package alignment
+
+import (
+	"container/heap"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/rschio/align/parse"
+)
+
+// nodes are the edges of a graph whose nodes are labeled by strings.
+// Its vertices are the runes of the nodes, numbered in order, but
+// only the edges between nodes are kept. The last rune of a node
+// reaches the rune overlap of each node after it, and every other
+// rune reaches the next rune of its node.
+type nodes struct {
+	// start[i] is the first vertex of the node i,
+	// start[len(start)-1] is the number of vertices.
+	start []int
+	// ends has the bits of the last vertices of the nodes.
+	ends []uint64
+	// out are the vertices reached from the last vertex of each
+	// node, and loop tells if it reaches itself, a node of one
+	// rune after the overlap linked to itself.
+	out     [][]int
+	loop    []bool
+	overlap int
+	// source tells if the first rune of each node is a
+	// vertex without in edges.
+	source []bool
+	// depth is the least depth of the runes of each node reached
+	// from the nodes before it, and height the height of its
+	// last rune, as in boundaryDist. They are -1 when unknown.
+	depth, height []int
+}
+
+// NewSeqBase returns the alignment of sequence to sg as NewBase,
+// without the expansion of sg in a parse.Graph of one node per
+// rune. The vertices of the grid are still the runes of the nodes
+// in order, so Labels keeps a rune and Order counts a vertex for
+// each of them, but the edges, loops, depths and heights are only
+// kept per node. Graph Position returns the node and the offset
+// of a vertex. sg must be valid.
+func NewSeqBase(sg *parse.SeqGraph, sequence string, score ScoreFn, opts ...Option) *Base {
+	g := new(Base)
+	g.Score = score
+	g.EndGaps = Glocal
+	g.nodes, g.Labels = newNodes(sg)
+	g.labelSet = runeSet(g.Labels)
+	g.SetSeq(sequence)
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+func newNodes(sg *parse.SeqGraph) (*nodes, []rune) {
+	n := len(sg.Labels)
+	size := 0
+	for _, l := range sg.Labels {
+		size += utf8.RuneCountInString(l)
+	}
+	labels := make([]rune, 0, size)
+	ns := &nodes{
+		start:   make([]int, n+1),
+		ends:    make([]uint64, (size+63)/64),
+		out:     make([][]int, n),
+		loop:    make([]bool, n),
+		source:  make([]bool, n),
+		overlap: sg.Overlap,
+	}
+	for i, l := range sg.Labels {
+		ns.start[i] = len(labels)
+		for _, r := range l {
+			labels = append(labels, r)
+		}
+		last := len(labels) - 1
+		ns.ends[last/64] |= 1 << uint(last%64)
+	}
+	ns.start[n] = len(labels)
+
+	in := make([][]int, n)
+	for _, e := range removeDupEdgs(sg.Edges) {
+		u, w := e[0], e[1]
+		if u == w && ns.size(u)-ns.overlap == 1 {
+			ns.loop[u] = true
+			continue
+		}
+		ns.out[u] = append(ns.out[u], ns.start[w]+ns.overlap)
+		in[w] = append(in[w], u)
+	}
+	for i := range ns.source {
+		ns.source[i] = ns.overlap > 0 || len(in[i]) == 0
+	}
+	ns.depth = ns.depths()
+	ns.height = ns.heights(in)
+	return ns, labels
+}
+
+// size returns the number of runes of the node i.
+func (ns *nodes) size(i int) int {
+	return ns.start[i+1] - ns.start[i]
+}
+
+// node returns the node of the vertex v and the offset of v in it.
+func (ns *nodes) node(v int) (i, offset int) {
+	i = sort.Search(len(ns.start)-1, func(i int) bool {
+		return ns.start[i+1] > v
+	})
+	return i, v - ns.start[i]
+}
+
+// last tells if v is the last vertex of its node.
+func (ns *nodes) last(v int) bool {
+	return ns.ends[v/64]&(1<<uint(v%64)) != 0
+}
+
+// isLoop tells if the vertex v is linked to itself.
+func (ns *nodes) isLoop(v int) bool {
+	if !ns.last(v) {
+		return false
+	}
+	i, _ := ns.node(v)
+	return ns.loop[i]
+}
+
+// next returns the vertices reached from v, without itself. The
+// vertices of the node but the last reach only v+1, returned as
+// next with a nil list.
+func (ns *nodes) next(v int) (next int, list []int) {
+	if !ns.last(v) {
+		return v + 1, nil
+	}
+	i, _ := ns.node(v)
+	return -1, ns.out[i]
+}
+
+// depthAt returns the depth of the rune j of the node i,
+// where depth is the depth of the node.
+func (ns *nodes) depthAt(i, j, depth int) int {
+	d := -1
+	if ns.source[i] {
+		d = j
+	}
+	if j >= ns.overlap && depth >= 0 {
+		if e := depth + j - ns.overlap; d < 0 || e < d {
+			d = e
+		}
+	}
+	return d
+}
+
+// depths returns the depth of each node, the least depth of its
+// entered rune from the nodes before it, found by Dijkstra over
+// the nodes, keyed by the depth of their last runes.
+func (ns *nodes) depths() []int {
+	n := len(ns.out)
+	depth := make([]int, n)
+	lastDepth := make([]int, n)
+	var q distQueue
+	for i := range depth {
+		depth[i] = -1
+		lastDepth[i] = ns.depthAt(i, ns.size(i)-1, -1)
+		if lastDepth[i] >= 0 {
+			heap.Push(&q, distItem{i, int64(lastDepth[i])})
+		}
+	}
+	for q.Len() > 0 {
+		it := heap.Pop(&q).(distItem)
+		u := it.v
+		if it.d > int64(lastDepth[u]) {
+			continue
+		}
+		for _, v := range ns.out[u] {
+			w, _ := ns.node(v)
+			if d := lastDepth[u] + 1; depth[w] < 0 || d < depth[w] {
+				depth[w] = d
+				last := ns.depthAt(w, ns.size(w)-1, d)
+				if last < lastDepth[w] || lastDepth[w] < 0 {
+					lastDepth[w] = last
+					heap.Push(&q, distItem{w, int64(last)})
+				}
+			}
+		}
+	}
+	return depth
+}
+
+// heights returns the height of the last rune of each node.
+func (ns *nodes) heights(in [][]int) []int {
+	n := len(ns.out)
+	height := make([]int, n)
+	var q distQueue
+	for i := range height {
+		height[i] = -1
+		if len(ns.out[i]) == 0 {
+			height[i] = 0
+			heap.Push(&q, distItem{i, 0})
+		}
+	}
+	for q.Len() > 0 {
+		it := heap.Pop(&q).(distItem)
+		w := it.v
+		if it.d > int64(height[w]) {
+			continue
+		}
+		// The edges reach the rune overlap of w.
+		h := height[w] + ns.size(w) - ns.overlap
+		for _, u := range in[w] {
+			if height[u] < 0 || h < height[u] {
+				height[u] = h
+				heap.Push(&q, distItem{u, int64(h)})
+			}
+		}
+	}
+	return height
+}
+
+// vertexDepth returns the depth of the vertex v.
+func (ns *nodes) vertexDepth(v int) int {
+	i, j := ns.node(v)
+	return ns.depthAt(i, j, ns.depth[i])
+}
+
+// vertexHeight returns the height of the vertex v.
+func (ns *nodes) vertexHeight(v int) int {
+	i, j := ns.node(v)
+	if ns.height[i] < 0 {
+		return -1
+	}
+	return ns.size(i) - 1 - j + ns.height[i]
+}
+
+// maxDepth returns the largest depth of a vertex, the
+// depths grow along the runes of a node.
+func (ns *nodes) maxDepth() int {
+	max := 0
+	for i := range ns.out {
+		if d := ns.depthAt(i, ns.size(i)-1, ns.depth[i]); d > max {
+			max = d
+		}
+	}
+	return max
+}
+
+// maxHeight returns the largest height of a vertex, the
+// heights fall along the runes of a node.
+func (ns *nodes) maxHeight() int {
+	max := 0
+	for i := range ns.out {
+		if h := ns.vertexHeight(ns.start[i]); h > max {
+			max = h
+		}
+	}
+	return max
+}
+
+// visitNodes visits the edges leaving v, a vertex before the last
+// row, as visit, in the same order: horizontals, the vertical and
+// diagonals, where the loop takes the place of the vertical.
+func (g *Graph) visitNodes(v int, do func(w int, c int64) bool) bool {
+	vertices := len(g.Labels)
+	vi := v % vertices
+	row := v / vertices
+	offset := v - vi
+	gap := g.Score(space, 'A')
+	next, list := g.nodes.next(vi)
+	if next >= 0 {
+		if do(next+offset, gap) {
+			return true
+		}
+	}
+	for _, w := range list {
+		if do(w+offset, gap) {
+			return true
+		}
+	}
+	offset += vertices
+	seq := g.SeqLabels[row+1]
+	if g.nodes.isLoop(vi) {
+		if do(vi+offset, g.Score(seq, g.Labels[vi])) {
+			return true
+		}
+	} else if do(vi+offset, g.Score('A', space)) {
+		return true
+	}
+	if next >= 0 {
+		if do(next+offset, g.Score(seq, g.Labels[next])) {
+			return true
+		}
+	}
+	for _, w := range list {
+		if do(w+offset, g.Score(seq, g.Labels[w])) {
+			return true
+		}
+	}
+	return g.VisitFromRow(v, do)
+}
+
+// loop tells if the vertex vi of the graph aligned has a loop.
+func (g *Graph) loop(vi int) bool {
+	if g.nodes != nil {
+		return g.nodes.isLoop(vi)
+	}
+	return g.Loops[vi]
+}
+
+// Position returns the node of the vertex vi of the graph aligned,
+// an index of parse.SeqGraph.Labels, and the offset of the rune of
+// vi in its label. In a graph of one rune per node the node is vi.
+func (g *Graph) Position(vi int) (node, offset int) {
+	if g.nodes != nil {
+		return g.nodes.node(vi)
+	}
+	return vi, 0
+}
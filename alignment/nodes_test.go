@@ -0,0 +1,81 @@
+package alignment
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/rschio/align/parse"
+)
+
+// randomSeqGraph returns a graph of n nodes with labels of up
+// to 8 runes past the overlap and random edges, with loops.
+func randomSeqGraph(r *rand.Rand, n, overlap int) *parse.SeqGraph {
+	sg := &parse.SeqGraph{Overlap: overlap}
+	for i := 0; i < n; i++ {
+		sg.Labels = append(sg.Labels, randomSeq(r, overlap+1+r.Intn(8)))
+	}
+	for i := 0; i < 2*n; i++ {
+		sg.Edges = append(sg.Edges, [2]int{r.Intn(n), r.Intn(n)})
+	}
+	return sg
+}
+
+func TestSeqBase(t *testing.T) {
+	r := rand.New(rand.NewSource(4))
+	opts := []Option{
+		WithEndGaps(Global),
+		WithEndGaps(Glocal),
+		WithEndGaps(Overlap),
+		WithEndGaps(LocalEnds),
+	}
+	for i := 0; i < 100; i++ {
+		sg := randomSeqGraph(r, 1+r.Intn(10), r.Intn(3))
+		if err := sg.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		pg := sg.Expand()
+		seq := randomSeq(r, 1+r.Intn(30))
+		for k, opt := range opts {
+			for _, score := range []ScoreFn{weight, mismatch2} {
+				g := NewSeqBase(sg, seq, score, opt, WithClip(1)).Graph()
+				if g.Order() != NewBase(pg, seq, score).Graph().Order() {
+					t.Fatalf("%d: invalid order want: %d, got: %d", i,
+						NewBase(pg, seq, score).Graph().Order(), g.Order())
+				}
+				_, want := NewBase(pg, seq, score, opt, WithClip(1)).Graph().ShortestPath()
+				searchers := []Searcher{
+					new(Dijkstra), new(POA), new(BitParallel),
+					NewAStar(SeedHeuristic(4)), NewLinear(0),
+				}
+				for j, s := range searchers {
+					path, dist := s.ShortestPath(g)
+					if dist != want {
+						t.Fatalf("%d %d %d: invalid distance want: %d, got: %d", i, k, j, want, dist)
+					}
+					if c := pathCost(g, path); dist != -1 && c != dist {
+						t.Fatalf("%d %d %d: invalid path cost want: %d, got: %d", i, k, j, dist, c)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSeqBasePosition(t *testing.T) {
+	sg := &parse.SeqGraph{
+		Labels: []string{"ACGT", "TTT", "GCA"},
+		Edges:  [][2]int{{0, 1}, {1, 2}, {0, 2}},
+	}
+	g := NewSeqBase(sg, "ACGTGCA", weight).Graph()
+	path, dist := g.ShortestPath()
+	if dist != 0 {
+		t.Fatalf("invalid distance want: 0, got: %d", dist)
+	}
+	a := g.Alignment(path, dist)
+	want := [][2]int{{0, 0}, {0, 1}, {0, 2}, {0, 3}, {2, 0}, {2, 1}, {2, 2}}
+	for i, v := range a.Nodes {
+		if n, off := g.Position(v); n != want[i][0] || off != want[i][1] {
+			t.Fatalf("%d: invalid position want: %v, got: [%d %d]", i, want[i], n, off)
+		}
+	}
+}
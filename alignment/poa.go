@@ -0,0 +1,166 @@
+package alignment
+
+import (
+	"strconv"
+
+	"github.com/rschio/align/parse"
+	"github.com/rschio/graph"
+)
+
+// POA is a Searcher of graphs without cycles, as in partial order
+// alignment. The vertices of the grid are relaxed once, row by row
+// and in topological order inside a row, so no queue is needed.
+// Loops are not cycles of the grid, they link a row to the next.
+//
+// The graphs with cycles are searched with Dijkstra.
+type POA struct {
+	dist     []int64
+	parent   []int
+	fallback Dijkstra
+	v        int
+}
+
+func (p *POA) ShortestPath(g *Graph) (path []int, dist int64) {
+	order, ok := g.TopSort()
+	if !ok {
+		return p.fallback.ShortestPath(g)
+	}
+	n := g.Order()
+	if cap(p.dist) < n {
+		p.dist = make([]int64, n)
+		p.parent = make([]int, n)
+	}
+	p.dist, p.parent = p.dist[:n], p.parent[:n]
+	for i := range p.dist {
+		p.dist[i], p.parent[i] = -1, -1
+	}
+	p.dist[g.Src] = 0
+	p.v = g.Src
+	visit := g.visitor(p.relax)
+	visit(g.Src)
+	vertices := len(g.Labels)
+	for row := range g.SeqLabels {
+		for _, vi := range order {
+			p.v = row*vertices + vi
+			if p.dist[p.v] != -1 {
+				visit(p.v)
+			}
+		}
+	}
+	path, dist = []int{}, p.dist[g.Dst]
+	if dist == -1 {
+		return path, dist
+	}
+	for v := g.Dst; v != -1; v = p.parent[v] {
+		path = append(path, v)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, dist
+}
+
+func (p *POA) relax(w int, c int64) bool {
+	if c < 0 {
+		return false
+	}
+	alt := p.dist[p.v] + c
+	if p.dist[w] == -1 || alt < p.dist[w] {
+		p.dist[w], p.parent[w] = alt, p.v
+	}
+	return false
+}
+
+// TopSort returns the vertices of the graph aligned in topological
+// order, ok is false if the graph has cycles. Loops are ignored.
+func (g *Graph) TopSort() (order []int, ok bool) {
+	return graph.TopSort(seqGraph{g})
+}
+
+// seqGraph is the graph aligned, without its loops.
+type seqGraph struct {
+	g *Graph
+}
+
+func (s seqGraph) Order() int {
+	return len(s.g.Labels)
+}
+
+func (s seqGraph) Visit(v int, do func(w int, c int64) bool) bool {
+	skip := false
+	s.g.successors(v, func(w int) {
+		if !skip && w != v {
+			skip = do(w, 0)
+		}
+	})
+	return skip
+}
+
+// Fold adds the sequence of a, an alignment to sg, to sg. The runes
+// aligned to equal runes reuse their nodes, the others, mismatched,
+// inserted or clipped, are new nodes, and the nodes of the sequence
+// are linked by edges. It returns the nodes spelling the sequence,
+// so a graph can be built from reads, folding each one after
+// aligning it to the graph of the ones before.
+func Fold(sg *parse.Graph, a *Alignment) []int {
+	nodes := make([]int, 0, len(a.seqLabels))
+	// The new nodes are named by their index,
+	// primed while the name is taken.
+	var ids map[string]bool
+	if len(sg.IDs) == len(sg.Nodes) {
+		ids = make(map[string]bool, len(sg.IDs))
+		for _, id := range sg.IDs {
+			ids[id] = true
+		}
+	}
+	add := func(r rune) {
+		v := len(sg.Nodes)
+		sg.Nodes = append(sg.Nodes, r)
+		if ids != nil {
+			id := strconv.Itoa(v)
+			for ids[id] {
+				id += "'"
+			}
+			ids[id] = true
+			sg.IDs = append(sg.IDs, id)
+		}
+		nodes = append(nodes, v)
+	}
+	for _, r := range a.seqLabels[:a.SeqStart] {
+		add(r)
+	}
+	seq, node := a.SeqStart, 0
+	for _, op := range a.Ops {
+		switch op {
+		case Match:
+			nodes = append(nodes, a.Nodes[node])
+			seq++
+			node++
+		case Mismatch:
+			add(a.seqLabels[seq])
+			seq++
+			node++
+		case Insertion:
+			add(a.seqLabels[seq])
+			seq++
+		case Deletion:
+			node++
+		}
+	}
+	for _, r := range a.seqLabels[a.SeqEnd:] {
+		add(r)
+	}
+
+	edges := make(map[[2]int]bool, len(sg.Edges))
+	for _, e := range sg.Edges {
+		edges[e] = true
+	}
+	for i := 1; i < len(nodes); i++ {
+		e := [2]int{nodes[i-1], nodes[i]}
+		if !edges[e] {
+			edges[e] = true
+			sg.Edges = append(sg.Edges, e)
+		}
+	}
+	return nodes
+}
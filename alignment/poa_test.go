@@ -0,0 +1,110 @@
+package alignment
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/rschio/align/parse"
+)
+
+// randomDAG returns a chain of the runes of ref with extra
+// forward edges and loops.
+func randomDAG(r *rand.Rand, ref string, extra int) *parse.Graph {
+	pg := chain(ref)
+	for i := 0; i < extra; i++ {
+		u := r.Intn(len(ref))
+		w := u + r.Intn(len(ref)-u)
+		pg.Edges = append(pg.Edges, [2]int{u, w})
+	}
+	return pg
+}
+
+func TestPOA(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	opts := []Option{
+		WithEndGaps(Global),
+		WithEndGaps(Glocal),
+		WithEndGaps(Overlap),
+		WithEndGaps(LocalEnds),
+	}
+	for i := 0; i < 100; i++ {
+		ref := randomSeq(r, 10+r.Intn(100))
+		pg := randomDAG(r, ref, r.Intn(10))
+		start := r.Intn(len(ref) / 2)
+		s := []rune(ref[start:])
+		for j := 0; j < len(s)/10; j++ {
+			s[r.Intn(len(s))] = 'T'
+		}
+		seq := string(s) + randomSeq(r, r.Intn(10))
+		for k, opt := range opts {
+			for _, score := range []ScoreFn{weight, mismatch2} {
+				g := NewBase(pg, seq, score, opt, WithClip(1)).Graph()
+				if _, ok := g.TopSort(); !ok {
+					t.Fatalf("%d: want acyclic graph", i)
+				}
+				_, want := g.ShortestPath()
+				path, dist := new(POA).ShortestPath(g)
+				if dist != want {
+					t.Fatalf("%d %d: invalid distance want: %d, got: %d", i, k, want, dist)
+				}
+				if c := pathCost(g, path); dist != -1 && c != dist {
+					t.Fatalf("%d %d: invalid path cost want: %d, got: %d", i, k, dist, c)
+				}
+			}
+		}
+	}
+}
+
+func TestPOACycles(t *testing.T) {
+	pg := chain("ACGTACGT")
+	pg.Edges = append(pg.Edges, [2]int{7, 0})
+	g := NewBase(pg, "GTACGTACGTAC", weight).Graph()
+	if _, ok := g.TopSort(); ok {
+		t.Fatal("want cyclic graph")
+	}
+	_, want := g.ShortestPath()
+	if _, dist := new(POA).ShortestPath(g); dist != want {
+		t.Fatalf("invalid distance want: %d, got: %d", want, dist)
+	}
+}
+
+func TestFold(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	ref := randomSeq(r, 200)
+	reads := []string{ref}
+	for i := 0; i < 10; i++ {
+		s := []rune(ref)
+		for j := 0; j < 5; j++ {
+			s[r.Intn(len(s))] = rune("ACGT"[r.Intn(4)])
+		}
+		cut := r.Intn(len(s))
+		reads = append(reads, string(s[:cut])+randomSeq(r, 3)+string(s[cut:]))
+	}
+	pg := chain(reads[0])
+	for _, read := range reads[1:] {
+		g := NewBase(pg, read, weight, WithEndGaps(Global)).Graph()
+		a := g.Alignment(new(POA).ShortestPath(g))
+		nodes := Fold(pg, a)
+		if s := spell(pg, nodes); s != read {
+			t.Fatalf("invalid folded nodes want: %s, got: %s", read, s)
+		}
+	}
+	for i, read := range reads {
+		g := NewBase(pg, read, weight, WithEndGaps(Global)).Graph()
+		if _, ok := g.TopSort(); !ok {
+			t.Fatal("want acyclic graph")
+		}
+		if _, dist := new(POA).ShortestPath(g); dist != 0 {
+			t.Fatalf("%d: invalid distance want: 0, got: %d", i, dist)
+		}
+	}
+}
+
+// spell returns the runes of the nodes of pg.
+func spell(pg *parse.Graph, nodes []int) string {
+	rs := make([]rune, len(nodes))
+	for i, v := range nodes {
+		rs[i] = pg.Nodes[v]
+	}
+	return string(rs)
+}
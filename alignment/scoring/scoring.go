@@ -0,0 +1,206 @@
+// Package scoring loads substitution matrices in the NCBI format
+// and converts them to the costs used by the alignment package.
+package scoring
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"unicode"
+)
+
+// space is the rune aligned to gaps by the alignment package.
+const space = '-'
+
+//go:embed matrices
+var matrices embed.FS
+
+// Matrix is a substitution matrix, the score of aligning two runes.
+type Matrix struct {
+	Alphabet []rune
+	scores   [][]int64
+	// ascii and index map a rune to its row.
+	ascii [unicode.MaxASCII + 1]int
+	index map[rune]int
+	// unknown is the row of the runes out of the
+	// alphabet, -1 if the matrix has no such row.
+	unknown int
+	min     int64
+	max     int64
+}
+
+// Load returns one of the embedded matrices:
+// BLOSUM62 or NUC.4.4.
+func Load(name string) (*Matrix, error) {
+	f, err := matrices.Open(path.Join("matrices", name))
+	if err != nil {
+		return nil, fmt.Errorf("unknown matrix: %s", name)
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// ReadFile reads a matrix in the NCBI format from fname.
+func ReadFile(fname string) (*Matrix, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads a matrix in the NCBI format: lines starting with #
+// are comments, the first line holds the alphabet and each of the
+// next lines holds a rune of the alphabet followed by its scores.
+func Parse(r io.Reader) (*Matrix, error) {
+	sc := bufio.NewScanner(r)
+	m := new(Matrix)
+	for sc.Scan() {
+		line := sc.Bytes()
+		bs := bytes.TrimSpace(line)
+		if len(bs) == 0 || bs[0] == '#' {
+			continue
+		}
+		fields := bytes.Fields(bs)
+		if m.Alphabet == nil {
+			for _, f := range fields {
+				rs := []rune(string(f))
+				if len(rs) != 1 {
+					return nil, fmt.Errorf("invalid alphabet: %s", line)
+				}
+				m.Alphabet = append(m.Alphabet, unicode.ToUpper(rs[0]))
+			}
+			continue
+		}
+		if len(m.scores) == len(m.Alphabet) {
+			return nil, fmt.Errorf("invalid line: %s: more rows than the alphabet", line)
+		}
+		row, err := parseRow(fields, m.Alphabet[len(m.scores)], len(m.Alphabet))
+		if err != nil {
+			return nil, fmt.Errorf("invalid line: %s: %v", line, err)
+		}
+		m.scores = append(m.scores, row)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(m.Alphabet) == 0 || len(m.scores) != len(m.Alphabet) {
+		return nil, fmt.Errorf("matrix must have %d rows, got: %d",
+			len(m.Alphabet), len(m.scores))
+	}
+	m.init()
+	return m, nil
+}
+
+// parseRow parses the row of the rune label, the rows
+// are in the order of the alphabet.
+func parseRow(fields [][]byte, label rune, n int) ([]int64, error) {
+	if len(fields) != n+1 {
+		return nil, fmt.Errorf("want %d scores, got: %d", n, len(fields)-1)
+	}
+	if rs := []rune(string(fields[0])); len(rs) != 1 || unicode.ToUpper(rs[0]) != label {
+		return nil, fmt.Errorf("want row %c, got: %s", label, fields[0])
+	}
+	row := make([]int64, n)
+	for i, f := range fields[1:] {
+		s, err := strconv.ParseInt(string(f), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		row[i] = s
+	}
+	return row, nil
+}
+
+func (m *Matrix) init() {
+	m.index = make(map[rune]int)
+	for i := range m.ascii {
+		m.ascii[i] = -1
+	}
+	for i, r := range m.Alphabet {
+		m.index[r] = i
+		if r <= unicode.MaxASCII {
+			m.ascii[r] = i
+		}
+	}
+	m.unknown = -1
+	for _, r := range []rune{'X', 'N'} {
+		if i, ok := m.index[r]; ok {
+			m.unknown = i
+			break
+		}
+	}
+	m.min, m.max = m.scores[0][0], m.scores[0][0]
+	for _, row := range m.scores {
+		for _, s := range row {
+			if s < m.min {
+				m.min = s
+			}
+			if s > m.max {
+				m.max = s
+			}
+		}
+	}
+}
+
+func (m *Matrix) row(r rune) int {
+	r = unicode.ToUpper(r)
+	if r <= unicode.MaxASCII {
+		if i := m.ascii[r]; i >= 0 {
+			return i
+		}
+		return m.unknown
+	}
+	if i, ok := m.index[r]; ok {
+		return i
+	}
+	return m.unknown
+}
+
+// Score returns the score of aligning a to b. The runes out of the
+// alphabet are scored as X, or N, or with the lowest score of the
+// matrix when it has neither.
+func (m *Matrix) Score(a, b rune) int64 {
+	i, j := m.row(a), m.row(b)
+	if i < 0 || j < 0 {
+		return m.min
+	}
+	return m.scores[i][j]
+}
+
+// Costs returns the cost function of the matrix with a linear gap
+// penalty, the cost of aligning a rune to a space.
+//
+// The alignment graph looks for the least cost, so each score s of
+// a rune of the sequence is converted to the non negative cost
+// max-s, where max is the highest score of the matrix: a rune of
+// the sequence aligned to a space costs gap+max and a rune of the
+// graph aligned to a space costs gap. An alignment of n runes of
+// the sequence costs n*max-score, whatever the length of the path,
+// so the least cost alignment of the whole sequence is the highest
+// scoring one. A rune left out of the alignment, with a clip cost
+// of max, scores 0.
+func (m *Matrix) Costs(gap int64) func(a, b rune) int64 {
+	return func(a, b rune) int64 {
+		switch {
+		case b == space:
+			return gap + m.max
+		case a == space:
+			return gap
+		}
+		return m.max - m.Score(a, b)
+	}
+}
+
+// AffineCosts converts the affine gap penalty, open+n*extend for
+// a gap of length n, to the costs of alignment.Affine of a graph
+// with the Costs of the matrix, as Costs does to the linear one.
+func (m *Matrix) AffineCosts(open, extend int64) (openCost, extendCost int64) {
+	return open, extend
+}
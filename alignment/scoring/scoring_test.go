@@ -0,0 +1,121 @@
+package scoring
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rschio/align/alignment"
+	"github.com/rschio/align/parse"
+)
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		matrix string
+		a, b   rune
+		want   int64
+	}{
+		{matrix: "BLOSUM62", a: 'W', b: 'W', want: 11},
+		{matrix: "BLOSUM62", a: 'A', b: 'R', want: -1},
+		{matrix: "BLOSUM62", a: 'y', b: 'F', want: 3},
+		{matrix: "BLOSUM62", a: 'J', b: 'A', want: 0},
+		{matrix: "NUC.4.4", a: 'a', b: 'A', want: 5},
+		{matrix: "NUC.4.4", a: 'A', b: 'R', want: 1},
+		{matrix: "NUC.4.4", a: 'N', b: 'C', want: -2},
+	}
+	for _, tt := range tests {
+		m, err := Load(tt.matrix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := m.Score(tt.a, tt.b); got != tt.want {
+			t.Errorf("%s: score of %c %c want: %d, got: %d",
+				tt.matrix, tt.a, tt.b, tt.want, got)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{name: "missing row", in: "  A C\nA 1 0\n"},
+		{name: "missing score", in: "  A C\nA 1 0\nC 1\n"},
+		{name: "invalid score", in: "  A C\nA 1 0\nC 1 x\n"},
+		{name: "reordered rows", in: "  A C\nC 0 1\nA 1 0\n"},
+		{name: "misspelled row", in: "  A C\nA 1 0\nG 0 1\n"},
+		{name: "extra row", in: "  A C\nA 1 0\nC 0 1\nC 0 1\n"},
+	}
+	for _, tt := range tests {
+		if _, err := Parse(strings.NewReader(tt.in)); err == nil {
+			t.Errorf("%s: want error, got nil", tt.name)
+		}
+	}
+}
+
+func TestCosts(t *testing.T) {
+	m, err := Load("BLOSUM62")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const protein = "MKTAYIAKQR"
+	pg := &parse.Graph{Nodes: []rune(protein)}
+	for i := 1; i < len(pg.Nodes); i++ {
+		pg.Edges = append(pg.Edges, [2]int{i - 1, i})
+	}
+	g := alignment.NewBase(pg, protein, m.Costs(4)).Graph()
+	_, dist := g.ShortestPath()
+	var score int64
+	for _, r := range protein {
+		score += m.Score(r, r)
+	}
+	n := int64(len(protein))
+	if want := m.max*n - score; dist != want {
+		t.Fatalf("invalid distance want: %d, got: %d", want, dist)
+	}
+}
+
+func TestCostsPathLength(t *testing.T) {
+	m, err := Load("BLOSUM62")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// MK(TAY|T)IAKQR, the bubble has paths of different lengths.
+	bubble := &parse.Graph{
+		Nodes: []rune("MKTAYTIAKQR"),
+		Edges: [][2]int{
+			{0, 1}, {1, 2}, {2, 3}, {3, 4}, {4, 6}, {1, 5}, {5, 6},
+			{6, 7}, {7, 8}, {8, 9}, {9, 10},
+		},
+	}
+	tests := []struct {
+		name  string
+		pg    *parse.Graph
+		seq   string
+		cigar string
+	}{
+		{name: "same", pg: &parse.Graph{Nodes: []rune("AAAA"),
+			Edges: [][2]int{{0, 1}, {1, 2}, {2, 3}}}, seq: "AAAA", cigar: "4="},
+		{name: "long branch", pg: bubble, seq: "MKTAYIAKQR", cigar: "10="},
+		{name: "short branch", pg: bubble, seq: "MKTIAKQR", cigar: "8="},
+		{name: "suffix", pg: bubble, seq: "AKQR", cigar: "4="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := alignment.NewBase(tt.pg, tt.seq, m.Costs(4),
+				alignment.WithEndGaps(alignment.Glocal)).Graph()
+			path, dist := g.ShortestPath()
+			a := g.Alignment(path, dist)
+			if cigar := a.Cigar(); cigar != tt.cigar {
+				t.Fatalf("invalid cigar want: %s, got: %s", tt.cigar, cigar)
+			}
+			var score int64
+			for _, r := range tt.seq {
+				score += m.Score(r, r)
+			}
+			if want := m.max*int64(len(tt.seq)) - score; dist != want {
+				t.Fatalf("invalid distance want: %d, got: %d", want, dist)
+			}
+		})
+	}
+}
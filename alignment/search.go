@@ -0,0 +1,103 @@
+package alignment
+
+import "github.com/rschio/graph"
+
+// Searcher finds the shortest path, from Src to Dst, of
+// the alignment graph. dist is -1 and path is empty if
+// Dst cannot be reached.
+type Searcher interface {
+	ShortestPath(g *Graph) (path []int, dist int64)
+}
+
+// Dijkstra is the Searcher of Graph.ShortestPath. It keeps its
+// queue and its distance buffers between searches, so it must
+// not be used by more than one goroutine at a time. In a graph
+// with a Band the buffers are sized to the band.
+type Dijkstra struct {
+	dist    []int64
+	parent  []int
+	q       graph.DistQueue
+	maxCost int64
+	v       int
+	// band, if not nil, numbers the vertices of
+	// the band, the indices of the buffers.
+	band *bandIndex
+}
+
+func (d *Dijkstra) ShortestPath(g *Graph) (path []int, dist int64) {
+	n := g.Order()
+	src, dst := g.Src, g.Dst
+	d.band = nil
+	if g.Band != nil {
+		d.band = newBandIndex(g)
+		n = d.band.size()
+		src, dst = d.band.index(src), d.band.index(dst)
+	}
+	if cap(d.dist) < n {
+		d.dist = make([]int64, n)
+		d.parent = make([]int, n)
+	}
+	d.dist, d.parent = d.dist[:n], d.parent[:n]
+	for i := range d.dist {
+		d.dist[i], d.parent[i] = -1, -1
+	}
+	if max := g.MaxCost(); d.q == nil || max != d.maxCost {
+		d.q, d.maxCost = newQueue(max), max
+	}
+	d.q.SetDist(d.dist)
+	d.q.Push(src, 0)
+	do, doBand := d.relax, d.relaxBand
+	for d.q.Len() > 0 {
+		d.v = d.q.Pop()
+		if d.v == dst {
+			break
+		}
+		if d.band != nil {
+			// relaxBand skips the vertices out of the band.
+			g.visit(d.band.vertex(d.v), doBand)
+		} else {
+			g.visit(d.v, do)
+		}
+	}
+	path, dist = []int{}, d.dist[dst]
+	if dist == -1 {
+		return path, dist
+	}
+	for v := dst; v != -1; v = d.parent[v] {
+		if d.band != nil {
+			path = append(path, d.band.vertex(v))
+		} else {
+			path = append(path, v)
+		}
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, dist
+}
+
+// relaxBand relaxes the edge to w, if w is in the band,
+// as relax in the indices of the band.
+func (d *Dijkstra) relaxBand(w int, c int64) bool {
+	i := d.band.index(w)
+	if i < 0 {
+		return false
+	}
+	return d.relax(i, c)
+}
+
+func (d *Dijkstra) relax(w int, c int64) bool {
+	if c < 0 {
+		return false
+	}
+	alt := d.dist[d.v] + c
+	switch {
+	case d.dist[w] == -1:
+		d.parent[w] = d.v
+		d.q.Push(w, alt)
+	case alt < d.dist[w]:
+		d.parent[w] = d.v
+		d.q.Fix(w, alt)
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package debruijn
+
+import (
+	"io"
+
+	"github.com/rschio/align/fastx"
+	"github.com/rschio/align/parse"
+)
+
+// Builder builds a DeBruijn graph from many sequences, added one
+// at a time. The k-mers spanning the end of a sequence and the
+// start of the next are not added.
+type Builder struct {
+	g *DeBruijn
+	m map[string]int
+	// edges maps each edge to its index in the graph.
+	edges     map[edge]int
+	k         int
+	canonical bool
+}
+
+// edge is an edge of the graph and the orientations of its ends.
+type edge struct {
+	e [2]int
+	r [2]bool
+}
+
+// NewBuilder returns a builder of the graph of the k-mers of the
+// sequences added, as NewDeBruijn.
+func NewBuilder(k int) *Builder {
+	return newBuilder(k, false)
+}
+
+// NewCanonicalBuilder returns a builder of the graph of the
+// canonical k-mers of the sequences added, as NewCanonicalDeBruijn.
+func NewCanonicalBuilder(k int) *Builder {
+	return newBuilder(k, true)
+}
+
+func newBuilder(k int, canonical bool) *Builder {
+	b := &Builder{
+		g:         new(DeBruijn),
+		m:         make(map[string]int),
+		edges:     make(map[edge]int),
+		k:         k,
+		canonical: canonical,
+	}
+	if k > 1 {
+		b.g.K = k - 1
+	}
+	if canonical {
+		b.g.Reverse = [][2]bool{}
+	}
+	return b
+}
+
+// Add adds the k-mers of seq to the graph, a sequence shorter
+// than K runes adds nothing. The k-mers and edges already in the
+// graph are counted again.
+func (b *Builder) Add(seq []rune) {
+	n := b.g.K
+	if b.k <= 1 || len(seq) < n {
+		return
+	}
+	prev, prevRev := b.vertex(seq[:n])
+	for i := 1; i < len(seq)-n+1; i++ {
+		p, rev := b.vertex(seq[i : i+n])
+		b.edge(edge{[2]int{prev, p}, [2]bool{prevRev, rev}})
+		prev, prevRev = p, rev
+	}
+}
+
+// edge counts the edge e, adding it to the graph if it is new.
+// In a canonical graph e is the same edge of its other strand.
+func (b *Builder) edge(e edge) {
+	if b.canonical {
+		other := edge{
+			[2]int{e.e[1], e.e[0]},
+			[2]bool{!e.r[1], !e.r[0]},
+		}
+		if _, ok := b.edges[other]; ok {
+			e = other
+		}
+	}
+	if i, ok := b.edges[e]; ok {
+		b.g.EdgeCounts[i]++
+		return
+	}
+	b.edges[e] = len(b.g.Edges)
+	b.g.Edges = append(b.g.Edges, e.e)
+	b.g.EdgeCounts = append(b.g.EdgeCounts, 1)
+	if b.canonical {
+		b.g.Reverse = append(b.g.Reverse, e.r)
+	}
+}
+
+// AddFASTA adds the sequence of each record read from r, in the
+// FASTA or FASTQ format, plain or gzip compressed.
+func (b *Builder) AddFASTA(r io.Reader) error {
+	fr, err := fastx.NewReader(r)
+	if err != nil {
+		return err
+	}
+	for {
+		rec, err := fr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		b.Add([]rune(rec.Seq))
+	}
+}
+
+// Graph returns the graph of the sequences added, later
+// calls to Add update it. Its edges are not repeated, the
+// occurrences are counted in EdgeCounts.
+func (b *Builder) Graph() *DeBruijn {
+	return b.g
+}
+
+// vertex returns the vertex of label and whether
+// label is the reverse complement of its label.
+func (b *Builder) vertex(label []rune) (int, bool) {
+	str, rev := string(label), false
+	if b.canonical {
+		if rc := parse.ReverseComplement(str); rc < str {
+			str, rev = rc, true
+			label = []rune(rc)
+		}
+	}
+	p, ok := b.m[str]
+	if !ok {
+		p = len(b.g.Vertices)
+		b.g.Vertices = append(b.g.Vertices, label)
+		b.g.Counts = append(b.g.Counts, 0)
+		b.m[str] = p
+	}
+	b.g.Counts[p]++
+	return p, rev
+}
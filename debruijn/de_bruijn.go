@@ -4,12 +4,24 @@ import (
 	"bytes"
 	"fmt"
 	"strings"
+
+	"github.com/rschio/align/parse"
 )
 
 type DeBruijn struct {
 	Vertices [][]rune
 	Edges    [][2]int
 	K        int
+	// Reverse tells, for each edge, if its ends are read as the
+	// reverse complement of their labels. Each edge also links
+	// the ends in the opposite orientations, the other strand.
+	// It is nil if the graph is not canonical.
+	Reverse [][2]bool
+	// Counts are the occurrences of each vertex in the
+	// sequences, and EdgeCounts the ones of each edge. They
+	// are nil if the graph was not built by a Builder.
+	Counts     []int
+	EdgeCounts []int
 }
 
 func (g *DeBruijn) String() string {
@@ -27,37 +39,80 @@ func (g *DeBruijn) String() string {
 }
 
 func NewDeBruijn(seq []rune, k int) *DeBruijn {
+	return newDeBruijn(seq, k, false)
+}
+
+// NewCanonicalDeBruijn returns the graph of the canonical k-mers of
+// seq, a k-mer and its reverse complement are the same vertex,
+// labeled by the least of them. The orientation in which each edge
+// reads its ends is kept in Reverse.
+func NewCanonicalDeBruijn(seq []rune, k int) *DeBruijn {
+	return newDeBruijn(seq, k, true)
+}
+
+func newDeBruijn(seq []rune, k int, canonical bool) *DeBruijn {
 	if k <= 1 {
 		return new(DeBruijn)
 	}
-	g := new(DeBruijn)
-	g.K = k - 1
-	n := k - 1
-	m := make(map[string]int)
-
-	g.Vertices = append(g.Vertices, seq[:n])
-	m[string(seq[:n])] = 0
-	l := 1
-	prev := 0
-	for i := 1; i < len(seq)-n+1; i++ {
-		label := seq[i : i+n]
-		str := string(label)
-		p, ok := m[str]
-		if !ok {
-			g.Vertices = append(g.Vertices, label)
-			m[str] = l
-			p = l
-			l++
+	b := newBuilder(k, canonical)
+	b.Add(seq)
+	return b.Graph()
+}
+
+// Canonical tells if the k-mers of the graph are canonical.
+func (g *DeBruijn) Canonical() bool {
+	return g.Reverse != nil
+}
+
+// chains returns the labels of the strands of the vertices, the
+// vertices and, in a canonical graph, their reverse complements.
+func (g *DeBruijn) chains() [][]rune {
+	if !g.Canonical() {
+		return g.Vertices
+	}
+	labels := make([][]rune, 0, 2*len(g.Vertices))
+	labels = append(labels, g.Vertices...)
+	for _, v := range g.Vertices {
+		labels = append(labels, []rune(parse.ReverseComplement(string(v))))
+	}
+	return labels
+}
+
+// strands returns the edges between the chains, in a canonical
+// graph each edge links the chains of the orientations of its
+// ends and, in the other strand, the chains of the opposite ones.
+func (g *DeBruijn) strands() [][2]int {
+	if !g.Canonical() {
+		return g.Edges
+	}
+	n := len(g.Vertices)
+	chain := func(i int, rev bool) int {
+		if rev {
+			return i + n
 		}
-		g.Edges = append(g.Edges, [2]int{prev, p})
-		prev = p
+		return i
+	}
+	edges := make([][2]int, 0, 2*len(g.Edges))
+	for i, e := range g.Edges {
+		r := g.Reverse[i]
+		edges = append(edges,
+			[2]int{chain(e[0], r[0]), chain(e[1], r[1])},
+			[2]int{chain(e[1], !r[1]), chain(e[0], !r[0])})
 	}
-	return g
+	return edges
 }
 
 type ParseGraph struct {
 	Vertices []rune
 	Edges    [][2]int
+	// k is the number of runes of a vertex of the
+	// DeBruijn and n the number of its vertices.
+	k, n int
+	// unitigs are the unitigs of a graph built by ParseUnitigs,
+	// starts the first vertex of each one and the number of
+	// vertices. They are nil if the graph is built by Parse.
+	unitigs [][]int
+	starts  []int
 }
 
 func (g *ParseGraph) String() string {
@@ -74,11 +129,15 @@ func (g *ParseGraph) String() string {
 	return buf.String()
 }
 
+// Parse returns the graph with a vertex for each rune of the
+// vertices. A canonical graph is expanded in both strands, the
+// runes of the reverse complements of the vertices follow the
+// runes of the vertices.
 func (g *DeBruijn) Parse() *ParseGraph {
-	p := new(ParseGraph)
-	for i := range g.Vertices {
+	p := &ParseGraph{k: g.K, n: len(g.Vertices)}
+	for i, label := range g.chains() {
 		for j := 0; j < g.K; j++ {
-			p.Vertices = append(p.Vertices, g.Vertices[i][j])
+			p.Vertices = append(p.Vertices, label[j])
 			if j > 0 {
 				// Link the inner vertices.
 				offset := i * g.K
@@ -86,7 +145,7 @@ func (g *DeBruijn) Parse() *ParseGraph {
 			}
 		}
 	}
-	for _, e := range g.Edges {
+	for _, e := range g.strands() {
 		u := g.transform(e[0])
 		v := g.transform(e[1])
 		p.Edges = append(p.Edges, [2]int{u, v})
@@ -94,6 +153,52 @@ func (g *DeBruijn) Parse() *ParseGraph {
 	return p
 }
 
+// Vertex returns the vertex of the DeBruijn of the rune v, the
+// offset of v in its label, and whether v is a rune of the
+// reverse complement of the label. A rune of a unitig shared by
+// many vertices is returned as the rune of the first of them.
+func (g *ParseGraph) Vertex(v int) (vertex, offset int, reverse bool) {
+	chain, offset := v/g.k, v%g.k
+	if g.unitigs != nil {
+		chain, offset = g.unitigVertex(v)
+	}
+	return chain % g.n, offset, chain >= g.n
+}
+
+// Starts returns the first vertex of each chain of runes, the
+// vertices of the DeBruijn or its unitigs, followed by the number
+// of vertices.
+func (g *ParseGraph) Starts() []int {
+	if g.starts != nil {
+		return g.starts
+	}
+	if g.k == 0 {
+		return []int{0}
+	}
+	starts := make([]int, 0, len(g.Vertices)/g.k+1)
+	for v := 0; v <= len(g.Vertices); v += g.k {
+		starts = append(starts, v)
+	}
+	return starts
+}
+
+// SeqGraph returns the graph with a node for each vertex labeled
+// by its k-mer, without expanding it in one vertex per rune as
+// Parse. The edges skip the K-1 runes shared by the k-mers. A
+// canonical graph has the nodes of both strands, as in Parse.
+func (g *DeBruijn) SeqGraph() *parse.SeqGraph {
+	chains := g.chains()
+	sg := &parse.SeqGraph{
+		Labels:  make([]string, len(chains)),
+		Edges:   append([][2]int(nil), g.strands()...),
+		Overlap: g.K - 1,
+	}
+	for i, v := range chains {
+		sg.Labels[i] = string(v)
+	}
+	return sg
+}
+
 func (g *DeBruijn) transform(i int) int {
 	k := g.K
 	return k*i + k - 1
@@ -108,7 +213,8 @@ func (g *DeBruijn) Filter(seq []rune, threshold float64) {
 }
 
 // filter removes vertices where the distance between all slices of k size
-// of sequence and a k-mer are greater than the threshold.
+// of sequence and a k-mer are greater than the threshold. In a canonical
+// graph the reverse complement of the k-mer is compared too.
 func (g *DeBruijn) filter(seq []rune, fn DistanceFn, threshold float64) {
 	k := g.K
 	l := len(seq) - (k - 1)
@@ -116,8 +222,17 @@ func (g *DeBruijn) filter(seq []rune, fn DistanceFn, threshold float64) {
 	m := make(map[int]int)
 	count := 0
 	for i, v := range g.Vertices {
+		var rc []rune
+		if g.Canonical() {
+			rc = []rune(parse.ReverseComplement(string(v)))
+		}
 		for j := 0; j < l; j++ {
 			dist := fn(v, seq[j:j+k])
+			if rc != nil {
+				if d := fn(rc, seq[j:j+k]); d < dist {
+					dist = d
+				}
+			}
 			if dist <= t {
 				m[i] = count
 				count++
@@ -131,22 +246,105 @@ func (g *DeBruijn) filter(seq []rune, fn DistanceFn, threshold float64) {
 func (g *DeBruijn) remap(m map[int]int) {
 	vtx := make([][]rune, len(m))
 	edg := make([][2]int, 0, len(m))
+	var rev [][2]bool
+	if g.Canonical() {
+		rev = make([][2]bool, 0, len(m))
+	}
+	var counts, edgCounts []int
+	if g.HasCounts() {
+		counts = make([]int, len(m))
+		edgCounts = make([]int, 0, len(m))
+	}
 	for prev, new := range m {
 		vtx[new] = g.Vertices[prev]
+		if counts != nil {
+			counts[new] = g.Counts[prev]
+		}
 	}
-	for _, e := range g.Edges {
+	for i, e := range g.Edges {
 		v0 := e[0]
 		v1 := e[1]
 		nv0, ok0 := m[v0]
 		nv1, ok1 := m[v1]
 		if ok0 && ok1 {
 			edg = append(edg, [2]int{nv0, nv1})
+			if g.Canonical() {
+				rev = append(rev, g.Reverse[i])
+			}
+			if counts != nil {
+				edgCounts = append(edgCounts, g.EdgeCounts[i])
+			}
 		}
 	}
 	g.Vertices = vtx
 	g.Edges = edg
+	g.Reverse = rev
+	g.Counts = counts
+	g.EdgeCounts = edgCounts
+}
+
+// HasCounts tells if the graph has the counts of
+// all its vertices and edges.
+func (g *DeBruijn) HasCounts() bool {
+	return g.Counts != nil && len(g.Counts) == len(g.Vertices) &&
+		len(g.EdgeCounts) == len(g.Edges)
+}
+
+// FilterCount removes the vertices, the k-mers, that occur less
+// than min times in the sequences, as the ones with sequencing
+// errors, and the edges that occur less than min times. A graph
+// without counts is left unchanged.
+func (g *DeBruijn) FilterCount(min int) {
+	if !g.HasCounts() {
+		return
+	}
+	edg := g.Edges[:0]
+	rev := g.Reverse[:0]
+	edgCounts := g.EdgeCounts[:0]
+	for i, e := range g.Edges {
+		if g.EdgeCounts[i] < min {
+			continue
+		}
+		edg = append(edg, e)
+		edgCounts = append(edgCounts, g.EdgeCounts[i])
+		if g.Canonical() {
+			rev = append(rev, g.Reverse[i])
+		}
+	}
+	g.Edges, g.Reverse, g.EdgeCounts = edg, rev, edgCounts
+
+	m := make(map[int]int)
+	count := 0
+	for i, c := range g.Counts {
+		if c < min {
+			continue
+		}
+		m[i] = count
+		count++
+	}
+	g.remap(m)
+}
+
+// Histogram returns the number of vertices that occur
+// each number of times, h[c] vertices occur c times. It is
+// nil if the graph has no counts.
+func (g *DeBruijn) Histogram() []int {
+	if !g.HasCounts() {
+		return nil
+	}
+	var h []int
+	for _, c := range g.Counts {
+		for len(h) <= c {
+			h = append(h, 0)
+		}
+		h[c]++
+	}
+	return h
 }
 
+// FilterGaps removes the vertices with the rune gap, as the k-mers
+// of sequences joined by gaps. Builder adds many sequences without
+// the k-mers joining them.
 func (g *DeBruijn) FilterGaps(gap rune) {
 	m := make(map[int]int)
 	count := 0
@@ -1,9 +1,14 @@
 package debruijn
 
 import (
+	"bytes"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/rschio/align/parse"
 )
 
 func TestDeBruijn(t *testing.T) {
@@ -103,3 +108,327 @@ func readseq(t *testing.T, fname string) []rune {
 	}
 	return []rune(string(data))
 }
+
+func TestWriteGFA(t *testing.T) {
+	g := NewDeBruijn([]rune("ACGCGTCGAC"), 4)
+	buf := new(bytes.Buffer)
+	if err := g.WriteGFA(buf); err != nil {
+		t.Fatal(err)
+	}
+	pg, err := parse.ParseGFA(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Reading the GFA back must give the
+	// graph built by Parse.
+	p := g.Parse()
+	if string(pg.Nodes) != string(p.Vertices) {
+		t.Fatalf("invalid vertices want: %s, got: %s",
+			string(p.Vertices), string(pg.Nodes))
+	}
+	want := make(map[[2]int]bool)
+	for _, e := range p.Edges {
+		want[e] = true
+	}
+	got := make(map[[2]int]bool)
+	for _, e := range pg.Edges {
+		got[e] = true
+	}
+	if len(got) != len(want) {
+		t.Fatalf("invalid edges want: %v, got: %v", p.Edges, pg.Edges)
+	}
+	for e := range want {
+		if !got[e] {
+			t.Fatalf("missing edge: %v", e)
+		}
+	}
+
+	buf.Reset()
+	if err := p.WriteGFA(buf); err != nil {
+		t.Fatal(err)
+	}
+	pg, err = parse.ParseGFA(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(pg.Nodes) != string(p.Vertices) || len(pg.Edges) != len(want) {
+		t.Fatalf("invalid parse graph: %s %v", string(pg.Nodes), pg.Edges)
+	}
+}
+
+func TestSeqGraph(t *testing.T) {
+	g := NewDeBruijn([]rune("ACGCGTCGAACGT"), 4)
+	p := g.Parse()
+	sg := g.SeqGraph()
+	if err := sg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	pg := sg.Expand()
+	if string(pg.Nodes) != string(p.Vertices) {
+		t.Fatalf("invalid runes want: %s, got: %s", string(p.Vertices), string(pg.Nodes))
+	}
+	want := make(map[[2]int]bool)
+	for _, e := range p.Edges {
+		want[e] = true
+	}
+	for _, e := range pg.Edges {
+		if !want[e] {
+			t.Fatalf("unexpected edge: %v", e)
+		}
+		delete(want, e)
+	}
+	if len(want) != 0 {
+		t.Fatalf("missing edges: %v", want)
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	seq := "ACGCGTCGAACGTTAG"
+	g := NewCanonicalDeBruijn([]rune(seq), 4)
+	rc := NewCanonicalDeBruijn([]rune(parse.ReverseComplement(seq)), 4)
+	if len(g.Vertices) != len(rc.Vertices) {
+		t.Fatalf("invalid vertices want: %d, got: %d", len(g.Vertices), len(rc.Vertices))
+	}
+	labels := make(map[string]bool)
+	for _, v := range g.Vertices {
+		s := string(v)
+		if r := parse.ReverseComplement(s); r < s {
+			t.Fatalf("vertex not canonical: %s", s)
+		}
+		labels[s] = true
+	}
+	for _, v := range rc.Vertices {
+		if !labels[string(v)] {
+			t.Fatalf("missing vertex: %s", string(v))
+		}
+	}
+	if len(g.Reverse) != len(g.Edges) {
+		t.Fatalf("invalid orientations want: %d, got: %d", len(g.Edges), len(g.Reverse))
+	}
+
+	// Reading the GFA back must give the graph built by Parse.
+	buf := new(bytes.Buffer)
+	if err := g.WriteGFA(buf); err != nil {
+		t.Fatal(err)
+	}
+	pg, err := parse.ParseGFABidirected(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := g.Parse()
+	if string(pg.Nodes) != string(p.Vertices) {
+		t.Fatalf("invalid vertices want: %s, got: %s", string(p.Vertices), string(pg.Nodes))
+	}
+	want := make(map[[2]int]bool)
+	for _, e := range p.Edges {
+		want[e] = true
+	}
+	got := make(map[[2]int]bool)
+	for _, e := range pg.Edges {
+		got[e] = true
+	}
+	if len(got) != len(want) {
+		t.Fatalf("invalid edges want: %v, got: %v", p.Edges, pg.Edges)
+	}
+	for e := range want {
+		if !got[e] {
+			t.Fatalf("missing edge: %v", e)
+		}
+	}
+
+	n := len(g.Vertices)
+	for v := range p.Vertices {
+		vertex, offset, reverse := p.Vertex(v)
+		label := string(g.Vertices[vertex])
+		if reverse {
+			label = parse.ReverseComplement(label)
+		}
+		if r := []rune(label)[offset]; r != p.Vertices[v] {
+			t.Fatalf("invalid vertex %d want: %c, got: %c", v, r, p.Vertices[v])
+		}
+		if reverse != (v >= n*g.K) {
+			t.Fatalf("invalid orientation of vertex %d: %v", v, reverse)
+		}
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	seqs := []string{"ACGCGTCG", "TTAGCA", "GCGTCGAAC", "AC"}
+	b := NewBuilder(4)
+	for _, s := range seqs {
+		b.Add([]rune(s))
+	}
+	g := b.Graph()
+	kmers := make(map[string]bool)
+	edges := make(map[string]bool)
+	for _, s := range seqs {
+		for i := 0; i+3 <= len(s); i++ {
+			kmers[s[i:i+3]] = true
+		}
+		for i := 0; i+4 <= len(s); i++ {
+			edges[s[i:i+4]] = true
+		}
+	}
+	if len(g.Vertices) != len(kmers) {
+		t.Fatalf("invalid vertices want: %d, got: %d", len(kmers), len(g.Vertices))
+	}
+	for _, v := range g.Vertices {
+		if !kmers[string(v)] {
+			t.Fatalf("unexpected vertex: %s", string(v))
+		}
+	}
+	// No edge may join the end of a sequence to the next one.
+	for _, e := range g.Edges {
+		u, v := string(g.Vertices[e[0]]), string(g.Vertices[e[1]])
+		if !edges[u+v[len(v)-1:]] {
+			t.Fatalf("unexpected edge: %s -> %s", u, v)
+		}
+	}
+
+	fasta := ">a\nACGCGTCG\n>b first\nTTAG\nCA\n>c\nGCGTCGAAC\n>d\nAC\n"
+	fb := NewBuilder(4)
+	if err := fb.AddFASTA(strings.NewReader(fasta)); err != nil {
+		t.Fatal(err)
+	}
+	fg := fb.Graph()
+	if fg.String() != g.String() {
+		t.Fatalf("invalid graph want:\n%s\ngot:\n%s", g, fg)
+	}
+}
+
+func TestCounts(t *testing.T) {
+	b := NewBuilder(4)
+	for _, s := range []string{"ACGTAC", "ACGTAC", "CGTAT"} {
+		b.Add([]rune(s))
+	}
+	g := b.Graph()
+	counts := map[string]int{"ACG": 2, "CGT": 3, "GTA": 3, "TAC": 2, "TAT": 1}
+	for i, v := range g.Vertices {
+		if c := counts[string(v)]; g.Counts[i] != c {
+			t.Fatalf("invalid count of %s want: %d, got: %d", string(v), c, g.Counts[i])
+		}
+	}
+	edges := map[string]int{"ACGT": 2, "CGTA": 3, "GTAC": 2, "GTAT": 1}
+	if len(g.Edges) != len(edges) {
+		t.Fatalf("invalid edges want: %d, got: %d", len(edges), len(g.Edges))
+	}
+	for i, e := range g.Edges {
+		kmer := string(g.Vertices[e[0]]) + string(g.Vertices[e[1]][2:])
+		if c := edges[kmer]; g.EdgeCounts[i] != c {
+			t.Fatalf("invalid count of %s want: %d, got: %d", kmer, c, g.EdgeCounts[i])
+		}
+	}
+	h := g.Histogram()
+	if want := []int{0, 1, 2, 2}; fmt.Sprint(h) != fmt.Sprint(want) {
+		t.Fatalf("invalid histogram want: %v, got: %v", want, h)
+	}
+
+	g.FilterCount(2)
+	if len(g.Vertices) != 4 || len(g.Edges) != 3 {
+		t.Fatalf("invalid filtered graph: %d vertices, %d edges", len(g.Vertices), len(g.Edges))
+	}
+	for i, v := range g.Vertices {
+		if g.Counts[i] != counts[string(v)] {
+			t.Fatalf("invalid count of %s: %d", string(v), g.Counts[i])
+		}
+	}
+	for i, e := range g.Edges {
+		kmer := string(g.Vertices[e[0]]) + string(g.Vertices[e[1]][2:])
+		if g.EdgeCounts[i] != edges[kmer] {
+			t.Fatalf("invalid count of %s: %d", kmer, g.EdgeCounts[i])
+		}
+	}
+
+	// A graph without counts is not filtered.
+	lit := &DeBruijn{
+		Vertices: [][]rune{[]rune("ACG"), []rune("CGT")},
+		Edges:    [][2]int{{0, 1}},
+		K:        3,
+	}
+	lit.FilterCount(2)
+	if len(lit.Vertices) != 2 || len(lit.Edges) != 1 {
+		t.Fatalf("graph without counts filtered: %d vertices, %d edges", len(lit.Vertices), len(lit.Edges))
+	}
+	if h := lit.Histogram(); h != nil {
+		t.Fatalf("invalid histogram want: nil, got: %v", h)
+	}
+
+	// Both strands count the same canonical k-mers and edges.
+	cb := NewCanonicalBuilder(4)
+	cb.Add([]rune("ACGGTA"))
+	cb.Add([]rune(parse.ReverseComplement("ACGGTA")))
+	cg := cb.Graph()
+	for i, c := range cg.Counts {
+		if c != 2 {
+			t.Fatalf("invalid count of %s want: 2, got: %d", string(cg.Vertices[i]), c)
+		}
+	}
+	for i, c := range cg.EdgeCounts {
+		if c != 2 {
+			t.Fatalf("invalid count of edge %v want: 2, got: %d", cg.Edges[i], c)
+		}
+	}
+}
+
+func TestUnitigs(t *testing.T) {
+	seq := "ACGCGTCGAACGTTAGCGTCGTTTAGGCAT"
+	for _, g := range []*DeBruijn{
+		NewDeBruijn([]rune(seq), 4),
+		NewCanonicalDeBruijn([]rune(seq), 4),
+		NewDeBruijn([]rune("ACGTACGTACGT"), 4),
+	} {
+		chains := g.chains()
+		seen := make(map[int]bool)
+		for _, u := range g.Unitigs() {
+			for _, c := range u {
+				if seen[c] {
+					t.Fatalf("chain in many unitigs: %d", c)
+				}
+				seen[c] = true
+			}
+		}
+		if len(seen) != len(chains) {
+			t.Fatalf("invalid chains want: %d, got: %d", len(chains), len(seen))
+		}
+
+		p := g.Parse()
+		u := g.ParseUnitigs()
+		if len(u.Vertices) > len(p.Vertices) {
+			t.Fatalf("unitigs larger than parse: %d > %d", len(u.Vertices), len(p.Vertices))
+		}
+		starts := u.Starts()
+		if starts[len(starts)-1] != len(u.Vertices) {
+			t.Fatalf("invalid starts: %v", starts)
+		}
+		for v := range u.Vertices {
+			vertex, offset, reverse := u.Vertex(v)
+			label := string(g.Vertices[vertex])
+			if reverse {
+				label = parse.ReverseComplement(label)
+			}
+			if r := []rune(label)[offset]; r != u.Vertices[v] {
+				t.Fatalf("invalid vertex %d want: %c, got: %c", v, r, u.Vertices[v])
+			}
+		}
+		// Every edge of the chains must be spelled by the unitigs.
+		out := make(map[int][]int)
+		for _, e := range u.Edges {
+			out[e[0]] = append(out[e[0]], e[1])
+		}
+		kmers := make(map[string]bool)
+		for i := 1; i < len(starts); i++ {
+			for v := starts[i-1]; v <= starts[i]-g.K; v++ {
+				kmer := string(u.Vertices[v : v+g.K])
+				for _, w := range out[v+g.K-1] {
+					kmers[kmer+string(u.Vertices[w])] = true
+				}
+			}
+		}
+		for _, e := range g.strands() {
+			a, b := chains[e[0]], chains[e[1]]
+			if kmer := string(a) + string(b[g.K-1:]); !kmers[kmer] {
+				t.Fatalf("missing edge: %s", kmer)
+			}
+		}
+	}
+}
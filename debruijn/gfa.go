@@ -0,0 +1,66 @@
+package debruijn
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// WriteGFA writes the graph in the GFA 1 format, each vertex is a
+// segment named by its index and each edge is a link overlapping
+// the K-1 runes shared by the vertices. The links of a canonical
+// graph are oriented by Reverse.
+func (g *DeBruijn) WriteGFA(w io.Writer) error {
+	labels := make([]string, len(g.Vertices))
+	for i, v := range g.Vertices {
+		labels[i] = string(v)
+	}
+	return writeGFA(w, labels, g.Edges, g.Reverse, g.K-1)
+}
+
+// WriteGFA writes the graph in the GFA 1 format, each vertex is
+// a segment of one rune named by its index.
+func (g *ParseGraph) WriteGFA(w io.Writer) error {
+	labels := make([]string, len(g.Vertices))
+	for i, v := range g.Vertices {
+		labels[i] = string(v)
+	}
+	return writeGFA(w, labels, g.Edges, nil, 0)
+}
+
+// writeGFA writes the segments labels and the links edges, rev
+// tells the orientations of the ends of each edge, or is nil if
+// they are all forward.
+func writeGFA(w io.Writer, labels []string, edges [][2]int, rev [][2]bool, overlap int) error {
+	buf := bufio.NewWriter(w)
+	fmt.Fprintf(buf, "H\tVN:Z:1.0\n")
+	for i, l := range labels {
+		fmt.Fprintf(buf, "S\t%d\t%s\n", i, l)
+	}
+	// Edges may be repeated, but links must not.
+	type link struct {
+		e [2]int
+		r [2]bool
+	}
+	seen := make(map[link]struct{}, len(edges))
+	for i, e := range edges {
+		var r [2]bool
+		if rev != nil {
+			r = rev[i]
+		}
+		if _, ok := seen[link{e, r}]; ok {
+			continue
+		}
+		seen[link{e, r}] = struct{}{}
+		fmt.Fprintf(buf, "L\t%d\t%c\t%d\t%c\t%dM\n", e[0], orient(r[0]), e[1], orient(r[1]), overlap)
+	}
+	return buf.Flush()
+}
+
+// orient returns the GFA orientation of a reverse or forward end.
+func orient(reverse bool) rune {
+	if reverse {
+		return '-'
+	}
+	return '+'
+}
@@ -0,0 +1,125 @@
+package debruijn
+
+import "sort"
+
+// Unitigs returns the maximal non-branching paths of the graph, each
+// a list of the chains of Parse, the vertices and, in a canonical
+// graph, their reverse complements as n+i. Each chain is in exactly
+// one unitig, and an edge of the graph leaves the last chain of a
+// unitig to the first of another one, unless it links two
+// consecutive chains of a unitig.
+func (g *DeBruijn) Unitigs() [][]int {
+	n := len(g.chains())
+	in := make([][]int, n)
+	out := make([][]int, n)
+	for _, e := range removeDupEdges(g.strands()) {
+		out[e[0]] = append(out[e[0]], e[1])
+		in[e[1]] = append(in[e[1]], e[0])
+	}
+	// next returns the chain after u in its unitig, or -1.
+	next := func(u int) int {
+		if len(out[u]) != 1 {
+			return -1
+		}
+		w := out[u][0]
+		if len(in[w]) != 1 || w == u {
+			return -1
+		}
+		return w
+	}
+	var unitigs [][]int
+	seen := make([]bool, n)
+	walk := func(start int) {
+		unitig := []int{start}
+		seen[start] = true
+		for w := next(start); w >= 0 && !seen[w]; w = next(w) {
+			unitig = append(unitig, w)
+			seen[w] = true
+		}
+		unitigs = append(unitigs, unitig)
+	}
+	for v := 0; v < n; v++ {
+		if len(in[v]) == 1 && next(in[v][0]) == v {
+			continue
+		}
+		walk(v)
+	}
+	// The chains left are in cycles without branches.
+	for v := 0; v < n; v++ {
+		if !seen[v] {
+			walk(v)
+		}
+	}
+	return unitigs
+}
+
+// ParseUnitigs returns the graph with a vertex for each rune of the
+// unitigs, as Parse, but the K-1 runes shared by the consecutive
+// chains of a unitig are not repeated. The vertices of a unitig of m
+// chains are the K runes of its first chain followed by the last
+// rune of each other one.
+func (g *DeBruijn) ParseUnitigs() *ParseGraph {
+	chains := g.chains()
+	unitigs := g.Unitigs()
+	p := &ParseGraph{k: g.K, n: len(g.Vertices), unitigs: unitigs}
+	// unitig and last map each chain to its unitig and to
+	// the vertex of its last rune.
+	unitig := make([]int, len(chains))
+	last := make([]int, len(chains))
+	for i, u := range unitigs {
+		start := len(p.Vertices)
+		p.starts = append(p.starts, start)
+		for j, c := range u {
+			label := chains[c]
+			if j == 0 {
+				p.Vertices = append(p.Vertices, label...)
+			} else {
+				p.Vertices = append(p.Vertices, label[g.K-1])
+			}
+			unitig[c] = i
+			last[c] = len(p.Vertices) - 1
+		}
+		for v := start + 1; v < len(p.Vertices); v++ {
+			p.Edges = append(p.Edges, [2]int{v - 1, v})
+		}
+	}
+	p.starts = append(p.starts, len(p.Vertices))
+	for _, e := range removeDupEdges(g.strands()) {
+		u, w := e[0], e[1]
+		if unitig[u] == unitig[w] && last[w] == last[u]+1 {
+			// Consecutive chains of a unitig.
+			continue
+		}
+		p.Edges = append(p.Edges, [2]int{last[u], p.starts[unitig[w]] + g.K - 1})
+	}
+	return p
+}
+
+// removeDupEdges returns the edges without repetitions,
+// in the order they are first found.
+func removeDupEdges(edges [][2]int) [][2]int {
+	seen := make(map[[2]int]struct{}, len(edges))
+	dedup := make([][2]int, 0, len(edges))
+	for _, e := range edges {
+		if _, ok := seen[e]; ok {
+			continue
+		}
+		seen[e] = struct{}{}
+		dedup = append(dedup, e)
+	}
+	return dedup
+}
+
+// unitigVertex returns the chain of the rune v of a graph
+// built by ParseUnitigs and the offset of v in it.
+func (g *ParseGraph) unitigVertex(v int) (chain, offset int) {
+	i := sort.Search(len(g.starts)-1, func(i int) bool {
+		return g.starts[i+1] > v
+	})
+	offset = v - g.starts[i]
+	j := 0
+	if offset >= g.k {
+		j = offset - g.k + 1
+	}
+	return g.unitigs[i][j], offset - j
+}
@@ -0,0 +1,185 @@
+// Package fastx reads sequences in the FASTA and FASTQ formats,
+// plain or gzip compressed. The sequence of each record can be
+// aligned with alignment.NewBase or Base.SetSeq, or in batches
+// with Aligner.AlignRecords.
+package fastx
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"unicode"
+)
+
+// Record is a sequence of a FASTA or FASTQ file.
+type Record struct {
+	// Name is the first word of the header.
+	Name string
+	// Comment is the rest of the header.
+	Comment string
+	Seq     string
+	// Qual holds the qualities of FASTQ records,
+	// it is empty in FASTA records.
+	Qual string
+}
+
+type Reader struct {
+	r *bufio.Reader
+	// header is the header line of the next record.
+	header []byte
+	line   int
+}
+
+// NewReader returns a reader of FASTA or FASTQ records from r,
+// if r is gzip compressed it is decompressed.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		br = bufio.NewReader(zr)
+	}
+	return &Reader{r: br}, nil
+}
+
+// Read returns the next record, or io.EOF when there are no more.
+func (r *Reader) Read() (*Record, error) {
+	if r.header == nil {
+		line, err := r.nextLine()
+		if err != nil {
+			return nil, err
+		}
+		r.header = line
+	}
+	header := r.header
+	r.header = nil
+	switch header[0] {
+	case '>':
+		return r.readFASTA(header)
+	case '@':
+		return r.readFASTQ(header)
+	}
+	return nil, fmt.Errorf("line %d: invalid header: %s", r.line, header)
+}
+
+func (r *Reader) readFASTA(header []byte) (*Record, error) {
+	rec := newRecord(header)
+	var seq []byte
+	for {
+		line, err := r.nextLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line[0] == '>' || line[0] == '@' {
+			r.header = line
+			break
+		}
+		seq = append(seq, line...)
+	}
+	rec.Seq = string(seq)
+	return rec, nil
+}
+
+func (r *Reader) readFASTQ(header []byte) (*Record, error) {
+	rec := newRecord(header)
+	var seq, qual []byte
+	// The sequence may span many lines, until the + line.
+	for {
+		line, err := r.nextLine()
+		if err == io.EOF {
+			return nil, fmt.Errorf("line %d: missing + line", r.line)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line[0] == '+' {
+			break
+		}
+		seq = append(seq, line...)
+	}
+	// The qualities may span many lines,
+	// until they are as long as the sequence.
+	for len(qual) < len(seq) {
+		line, err := r.nextLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		qual = append(qual, line...)
+	}
+	if len(qual) != len(seq) {
+		return nil, fmt.Errorf("line %d: %s: want %d qualities, got: %d",
+			r.line, rec.Name, len(seq), len(qual))
+	}
+	rec.Seq = string(seq)
+	rec.Qual = string(qual)
+	return rec, nil
+}
+
+// newRecord returns the record of header, split
+// at its first space or tab.
+func newRecord(header []byte) *Record {
+	header = bytes.TrimSpace(header[1:])
+	i := bytes.IndexFunc(header, unicode.IsSpace)
+	if i < 0 {
+		return &Record{Name: string(header)}
+	}
+	return &Record{
+		Name:    string(header[:i]),
+		Comment: string(bytes.TrimSpace(header[i:])),
+	}
+}
+
+// nextLine returns the next line that is not empty,
+// without the line break.
+func (r *Reader) nextLine() ([]byte, error) {
+	for {
+		line, err := r.r.ReadBytes('\n')
+		if len(line) == 0 && err != nil {
+			return nil, err
+		}
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		r.line++
+		line = bytes.TrimRight(line, "\r\n")
+		if len(line) > 0 {
+			return line, nil
+		}
+	}
+}
+
+// ReadFile returns all the records of the file fname.
+func ReadFile(fname string) ([]*Record, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	var recs []*Record
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return recs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		recs = append(recs, rec)
+	}
+}
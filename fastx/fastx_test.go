@@ -0,0 +1,84 @@
+package fastx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+)
+
+const input = ">seq1 first sequence\nACGT\nAC\n\n>seq2\tsecond\tone\nTTTT\n" +
+	"@read1 a read\nACG\nT\n+\nII\nII\n@read2\nGG\n+read2\n!!\n"
+
+var want = []Record{
+	{Name: "seq1", Comment: "first sequence", Seq: "ACGTAC"},
+	{Name: "seq2", Comment: "second\tone", Seq: "TTTT"},
+	{Name: "read1", Comment: "a read", Seq: "ACGT", Qual: "IIII"},
+	{Name: "read2", Seq: "GG", Qual: "!!"},
+}
+
+func readAll(t *testing.T, r io.Reader) []*Record {
+	fr, err := NewReader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var recs []*Record
+	for {
+		rec, err := fr.Read()
+		if err == io.EOF {
+			return recs
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		recs = append(recs, rec)
+	}
+}
+
+func check(t *testing.T, recs []*Record) {
+	if len(recs) != len(want) {
+		t.Fatalf("want %d records, got: %d", len(want), len(recs))
+	}
+	for i, rec := range recs {
+		if *rec != want[i] {
+			t.Errorf("invalid record want: %+v, got: %+v", want[i], *rec)
+		}
+	}
+}
+
+func TestRead(t *testing.T) {
+	check(t, readAll(t, strings.NewReader(input)))
+}
+
+func TestReadGzip(t *testing.T) {
+	buf := new(bytes.Buffer)
+	zw := gzip.NewWriter(buf)
+	if _, err := zw.Write([]byte(input)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	check(t, readAll(t, buf))
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{name: "header", in: "ACGT\n"},
+		{name: "plus line", in: "@r\nACGT\n"},
+		{name: "qualities", in: "@r\nACGT\n+\nII\n"},
+	}
+	for _, tt := range tests {
+		r, err := NewReader(strings.NewReader(tt.in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.Read(); err == nil || err == io.EOF {
+			t.Errorf("%s: want error, got: %v", tt.name, err)
+		}
+	}
+}
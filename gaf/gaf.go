@@ -0,0 +1,174 @@
+// Package gaf writes alignments in the Graphical Alignment Format,
+// the tab separated format of read to graph alignments:
+// https://github.com/lh3/gfatools/blob/master/doc/rGFA.md#the-graph-alignment-format-gaf
+package gaf
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/rschio/align/alignment"
+	"github.com/rschio/align/parse"
+)
+
+// MissingMapQ is the mapping quality of unknown qualities.
+const MissingMapQ = 255
+
+// Step is a node of the path with its orientation.
+type Step struct {
+	ID      string
+	Reverse bool
+}
+
+// Record is a line of GAF.
+type Record struct {
+	QueryName  string
+	QueryLen   int
+	QueryStart int
+	QueryEnd   int
+	// Strand is the strand of the query, + or -.
+	Strand    byte
+	Path      []Step
+	PathLen   int
+	PathStart int
+	PathEnd   int
+	// Matches is the number of matching runes.
+	Matches int
+	// BlockLen is the number of columns of the alignment.
+	BlockLen int
+	MapQ     int
+	// Tags are the optional fields, in the SAM format TAG:TYPE:VALUE.
+	Tags []string
+}
+
+// NewRecord returns the record of the alignment of the query
+// named name to the graph g. When g was read from GFA the path
+// is made of its segments, otherwise the nodes are named by g.IDs,
+// or by their indices when g has no IDs. The strand is - when the
+// reverse complement of the query was aligned. The tags are the cg
+// CIGAR and the cs difference string of the alignment.
+func NewRecord(name string, a *alignment.Alignment, g *parse.Graph) *Record {
+	r := &Record{
+		QueryName:  name,
+		QueryLen:   a.SeqLen(),
+		QueryStart: a.SeqStart,
+		QueryEnd:   a.SeqEnd,
+		Strand:     '+',
+		Matches:    a.Matches(),
+		BlockLen:   len(a.Ops),
+		MapQ:       MissingMapQ,
+		Tags:       []string{"cg:Z:" + a.AlignedCigar(), "cs:Z:" + a.CS()},
+	}
+	if a.Reverse {
+		// The path is aligned to the reverse complement,
+		// the query positions are of the query.
+		r.Strand = '-'
+		r.QueryStart, r.QueryEnd = r.QueryLen-a.SeqEnd, r.QueryLen-a.SeqStart
+	}
+	if len(g.Segments) > 0 {
+		r.segmentPath(a.Nodes, g)
+		return r
+	}
+	r.Path = make([]Step, len(a.Nodes))
+	for i, v := range a.Nodes {
+		r.Path[i].ID = nodeID(g, v)
+	}
+	r.PathLen = len(a.Nodes)
+	r.PathEnd = len(a.Nodes)
+	return r
+}
+
+// segmentPath sets the path of the record in segment
+// coordinates, a step for each segment visited, reverse
+// in the reverse complement of a bidirected graph. The
+// runes a link overlaps, before the rune a step enters
+// its segment, are counted once in the path length.
+func (r *Record) segmentPath(nodes []int, g *parse.Graph) {
+	var last *parse.Segment
+	prev, reverse := 0, false
+	for _, v := range nodes {
+		s, off := g.Segment(v)
+		rev := s.IsReverse(v)
+		// A new step starts when the alignment leaves the
+		// segment, loops back to it or changes strand.
+		if s != last || off != prev+1 || rev != reverse {
+			n := s.End - s.Start
+			if last == nil {
+				r.PathStart = off
+			} else {
+				n -= off
+			}
+			r.Path = append(r.Path, Step{ID: s.Name, Reverse: rev})
+			r.PathLen += n
+		}
+		last, prev, reverse = s, off, rev
+	}
+	if last != nil {
+		r.PathEnd = r.PathLen - (last.End - last.Start - prev - 1)
+	}
+}
+
+func nodeID(g *parse.Graph, v int) string {
+	if v < len(g.IDs) {
+		return g.IDs[v]
+	}
+	return strconv.Itoa(v)
+}
+
+// PathString returns the path column, the nodes
+// prefixed by > if forward or < if reverse.
+func (r *Record) PathString() string {
+	var b strings.Builder
+	for _, s := range r.Path {
+		if s.Reverse {
+			b.WriteByte('<')
+		} else {
+			b.WriteByte('>')
+		}
+		b.WriteString(s.ID)
+	}
+	return b.String()
+}
+
+// String returns the record as a GAF line, without the line break.
+func (r *Record) String() string {
+	fields := []string{
+		r.QueryName,
+		strconv.Itoa(r.QueryLen),
+		strconv.Itoa(r.QueryStart),
+		strconv.Itoa(r.QueryEnd),
+		string(r.Strand),
+		r.PathString(),
+		strconv.Itoa(r.PathLen),
+		strconv.Itoa(r.PathStart),
+		strconv.Itoa(r.PathEnd),
+		strconv.Itoa(r.Matches),
+		strconv.Itoa(r.BlockLen),
+		strconv.Itoa(r.MapQ),
+	}
+	fields = append(fields, r.Tags...)
+	return strings.Join(fields, "\t")
+}
+
+type Writer struct {
+	w *bufio.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// Write writes the record as a GAF line.
+func (w *Writer) Write(r *Record) error {
+	if _, err := w.w.WriteString(r.String()); err != nil {
+		return err
+	}
+	return w.w.WriteByte('\n')
+}
+
+// Flush writes the buffered lines to the underlying writer.
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
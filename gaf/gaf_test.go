@@ -0,0 +1,136 @@
+package gaf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rschio/align/alignment"
+	"github.com/rschio/align/parse"
+)
+
+const graphText = `// A bubble: ACG(T|C)A.
+(a,A)
+(c,C)
+(g,G)
+(t,T)
+(c2,C)
+(a2,A)
+{a,c}
+{c,g}
+{g,t}
+{g,c2}
+{t,a2}
+{c2,a2}
+`
+
+func weight(a, b rune) int64 {
+	if a == b {
+		return 0
+	}
+	return 1
+}
+
+func TestWrite(t *testing.T) {
+	pg, err := parse.Parse(strings.NewReader(graphText))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := alignment.NewBase(pg, "ACGCA", weight).Graph()
+	path, dist := g.ShortestPath()
+	a := g.Alignment(path, dist)
+
+	buf := new(bytes.Buffer)
+	w := NewWriter(buf)
+	if err := w.Write(NewRecord("read1", a, pg)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := "read1\t5\t0\t5\t+\t>a>c>g>c2>a2\t5\t0\t5\t5\t5\t255\tcg:Z:5=\tcs:Z::5\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("invalid line\nwant: %q\ngot:  %q", want, got)
+	}
+}
+
+func TestWriteSegments(t *testing.T) {
+	gfa := "S\ts1\tTTACG\nS\ts2\tT\nS\ts3\tC\nS\ts4\tAGG\n" +
+		"L\ts1\t+\ts2\t+\t0M\nL\ts1\t+\ts3\t+\t0M\n" +
+		"L\ts2\t+\ts4\t+\t0M\nL\ts3\t+\ts4\t+\t0M\n"
+	pg, err := parse.ParseGFA(strings.NewReader(gfa))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := alignment.NewBase(pg, "ACGCAG", weight).Graph()
+	path, dist := g.ShortestPath()
+	r := NewRecord("read1", g.Alignment(path, dist), pg)
+	want := "read1\t6\t0\t6\t+\t>s1>s3>s4\t9\t2\t8\t6\t6\t255\tcg:Z:6=\tcs:Z::6"
+	if got := r.String(); got != want {
+		t.Fatalf("invalid line\nwant: %q\ngot:  %q", want, got)
+	}
+}
+
+func TestWriteOverlap(t *testing.T) {
+	// The path spells TTACGAAT, s2 overlaps the CG of s1.
+	gfa := "S\ts1\tTTACG\nS\ts2\tCGAAT\nL\ts1\t+\ts2\t+\t2M\n"
+	pg, err := parse.ParseGFA(strings.NewReader(gfa))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := alignment.NewBase(pg, "ACGAA", weight).Graph()
+	r := NewRecord("read1", g.Alignment(g.ShortestPath()), pg)
+	want := "read1\t5\t0\t5\t+\t>s1>s2\t8\t2\t7\t5\t5\t255\tcg:Z:5=\tcs:Z::5"
+	if got := r.String(); got != want {
+		t.Fatalf("invalid line\nwant: %q\ngot:  %q", want, got)
+	}
+}
+
+func TestWriteStrands(t *testing.T) {
+	gfa := "S\ts1\tTTACG\nS\ts2\tGGA\nL\ts1\t+\ts2\t-\t0M\n"
+	pg, err := parse.ParseGFABidirected(strings.NewReader(gfa))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// TCC is the reverse complement of s2.
+	g := alignment.NewBase(pg, "ACGTCC", weight).Graph()
+	r := NewRecord("read1", g.Alignment(g.ShortestPath()), pg)
+	want := "read1\t6\t0\t6\t+\t>s1<s2\t8\t2\t8\t6\t6\t255\tcg:Z:6=\tcs:Z::6"
+	if got := r.String(); got != want {
+		t.Fatalf("invalid line\nwant: %q\ngot:  %q", want, got)
+	}
+
+	// A read of the minus strand of a graph with one strand.
+	pg, err = parse.ParseGFA(strings.NewReader("S\ts1\tTTACG\nS\ts2\tTCC\nL\ts1\t+\ts2\t+\t0M\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := alignment.NewAligner(pg, weight)
+	a.Strands = true
+	al := a.Align(parse.ReverseComplement("TACGTCC"), new(alignment.Dijkstra))
+	r = NewRecord("read2", al, pg)
+	want = "read2\t7\t0\t7\t-\t>s1>s2\t8\t1\t8\t7\t7\t255\tcg:Z:7=\tcs:Z::7"
+	if got := r.String(); got != want {
+		t.Fatalf("invalid line\nwant: %q\ngot:  %q", want, got)
+	}
+}
+
+func TestWriteClipped(t *testing.T) {
+	pg, err := parse.Parse(strings.NewReader(graphText))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only CGC aligns, TT and GG are clipped.
+	g := alignment.NewBase(pg, "TTCGCGG", weight,
+		alignment.WithEndGaps(alignment.LocalEnds), alignment.WithClip(1)).Graph()
+	path, dist := g.ShortestPath()
+	a := g.Alignment(path, dist)
+	r := NewRecord("read1", a, pg)
+	want := "read1\t7\t2\t5\t+\t>c>g>c2\t3\t0\t3\t3\t3\t255\tcg:Z:3=\tcs:Z::3"
+	if got := r.String(); got != want {
+		t.Fatalf("invalid line\nwant: %q\ngot:  %q", want, got)
+	}
+	if c := a.Cigar(); c != "2S3=2S" {
+		t.Fatalf("invalid cigar want: 2S3=2S, got: %s", c)
+	}
+}
@@ -0,0 +1,378 @@
+// Package index finds where a sequence may align in a large graph.
+// The k-mers spelled by the paths of the graph are indexed, the
+// k-mers of the sequence found in the index are chained, and the
+// sequence is aligned only to the subgraph around the best chain.
+package index
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/rschio/align/alignment"
+	"github.com/rschio/align/debruijn"
+	"github.com/rschio/align/parse"
+)
+
+// Occurrence is a path of the graph spelling an indexed k-mer,
+// from the vertex Start to the vertex End.
+type Occurrence struct {
+	Start, End int
+}
+
+// Index maps the k-mers of a graph to their occurrences.
+type Index struct {
+	K int
+	// MaxOcc is the largest number of occurrences of a k-mer
+	// used as a seed, the k-mers more repetitive are skipped.
+	MaxOcc int
+	// MaxGap is the largest difference between the distance of
+	// two hits in the sequence and in the vertices of a chain.
+	MaxGap int
+	// Slack is the number of vertices added around the chain
+	// when the subgraph is extracted.
+	Slack int
+
+	g     *parse.Graph
+	kmers map[string][]Occurrence
+	in    [][]int
+	out   [][]int
+}
+
+// MaxPaths is the largest number of paths spelling k-mers
+// indexed from each vertex, it bounds the k-mers of vertices
+// followed by many branches.
+const MaxPaths = 16
+
+// MaxSteps is the largest number of vertices visited by the walks
+// from each vertex, it bounds the walks into branches that end
+// before spelling a k-mer.
+const MaxSteps = 4096
+
+// walkCount counts the paths indexed and the
+// vertices visited by the walks from a vertex.
+type walkCount struct {
+	paths, steps int
+}
+
+// New returns the index of the k-mers spelled by the paths of g.
+func New(g *parse.Graph, k int) *Index {
+	x := newIndex(g, k)
+	path := make([]rune, 0, k)
+	for v := range g.Nodes {
+		var n walkCount
+		x.walk(v, v, path, &n)
+	}
+	return x
+}
+
+// FromDeBruijn returns the index of the vertices of d, each one
+// a k-mer, where the vertices of the indexed graph are the ones
+// of d.Parse. A canonical d is indexed in both strands.
+func FromDeBruijn(d *debruijn.DeBruijn) *Index {
+	pg := d.Parse()
+	g := &parse.Graph{Nodes: pg.Vertices, Edges: pg.Edges}
+	x := newIndex(g, d.K)
+	n := len(d.Vertices)
+	for i, label := range d.Vertices {
+		x.addChain(i, string(label))
+		if d.Canonical() {
+			x.addChain(n+i, parse.ReverseComplement(string(label)))
+		}
+	}
+	return x
+}
+
+// addChain indexes the k-mer key spelled by the chain i of
+// K vertices.
+func (x *Index) addChain(i int, key string) {
+	start := i * x.K
+	x.kmers[key] = append(x.kmers[key], Occurrence{start, start + x.K - 1})
+}
+
+func newIndex(g *parse.Graph, k int) *Index {
+	x := &Index{
+		K:      k,
+		MaxOcc: 64,
+		MaxGap: 32,
+		Slack:  16,
+		g:      g,
+		kmers:  make(map[string][]Occurrence),
+		in:     make([][]int, len(g.Nodes)),
+		out:    make([][]int, len(g.Nodes)),
+	}
+	for _, e := range g.Edges {
+		x.out[e[0]] = append(x.out[e[0]], e[1])
+		x.in[e[1]] = append(x.in[e[1]], e[0])
+	}
+	return x
+}
+
+// walk indexes the k-mers starting at start following the
+// paths from v, n counts the paths already indexed and the
+// vertices already visited.
+func (x *Index) walk(start, v int, path []rune, n *walkCount) {
+	n.steps++
+	path = append(path, x.g.Nodes[v])
+	if len(path) == x.K {
+		key := string(path)
+		x.kmers[key] = append(x.kmers[key], Occurrence{start, v})
+		n.paths++
+		return
+	}
+	for _, w := range x.out[v] {
+		if n.paths >= MaxPaths || n.steps >= MaxSteps {
+			return
+		}
+		x.walk(start, w, path, n)
+	}
+}
+
+// Graph returns the indexed graph.
+func (x *Index) Graph() *parse.Graph {
+	return x.g
+}
+
+// Lookup returns the occurrences of the k-mer kmer.
+func (x *Index) Lookup(kmer string) []Occurrence {
+	return x.kmers[kmer]
+}
+
+// Hit is a k-mer of the sequence, starting at Pos,
+// found in the graph.
+type Hit struct {
+	Pos int
+	Occurrence
+}
+
+// Hits returns the hits of the k-mers of seq, sorted
+// by their positions and vertices.
+func (x *Index) Hits(seq string) []Hit {
+	rs := []rune(seq)
+	var hits []Hit
+	for i := 0; i+x.K <= len(rs); i++ {
+		occs := x.kmers[string(rs[i:i+x.K])]
+		if len(occs) > x.MaxOcc {
+			continue
+		}
+		for _, o := range occs {
+			hits = append(hits, Hit{Pos: i, Occurrence: o})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Pos != hits[j].Pos {
+			return hits[i].Pos < hits[j].Pos
+		}
+		return hits[i].Start < hits[j].Start
+	})
+	return hits
+}
+
+// Chain is a list of colinear hits, Score is the
+// number of runes of the sequence they cover.
+type Chain struct {
+	Hits  []Hit
+	Score int
+}
+
+// maxPred is the number of previous hits tried
+// as predecessors of a hit in a chain.
+const maxPred = 64
+
+// Chains returns the chains of hits, the best ones first. Two
+// hits are colinear when both the position and the vertex grow,
+// and their distances differ by at most x.MaxGap, so the chains
+// follow the vertices when their indices are close to a
+// topological order.
+func (x *Index) Chains(hits []Hit) []Chain {
+	score := make([]int, len(hits))
+	pred := make([]int, len(hits))
+	for j, h := range hits {
+		score[j], pred[j] = x.K, -1
+		for i := j - 1; i >= 0 && i >= j-maxPred; i-- {
+			p := hits[i]
+			dp, dv := h.Pos-p.Pos, h.Start-p.Start
+			if dp <= 0 || dv <= 0 || abs(dv-dp) > x.MaxGap {
+				continue
+			}
+			covered := dp
+			if covered > x.K {
+				covered = x.K
+			}
+			if s := score[i] + covered; s > score[j] {
+				score[j], pred[j] = s, i
+			}
+		}
+	}
+	order := make([]int, len(hits))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return score[order[i]] > score[order[j]]
+	})
+	used := make([]bool, len(hits))
+	var chains []Chain
+	for _, j := range order {
+		if used[j] {
+			continue
+		}
+		var c Chain
+		for i := j; i != -1 && !used[i]; i = pred[i] {
+			used[i] = true
+			c.Hits = append(c.Hits, hits[i])
+		}
+		for a, b := 0, len(c.Hits)-1; a < b; a, b = a+1, b-1 {
+			c.Hits[a], c.Hits[b] = c.Hits[b], c.Hits[a]
+		}
+		c.Score = chainScore(c.Hits, x.K)
+		chains = append(chains, c)
+	}
+	sort.SliceStable(chains, func(i, j int) bool {
+		return chains[i].Score > chains[j].Score
+	})
+	return chains
+}
+
+func chainScore(hits []Hit, k int) int {
+	s := 0
+	end := 0
+	for _, h := range hits {
+		start := h.Pos
+		if start < end {
+			start = end
+		}
+		s += h.Pos + k - start
+		end = h.Pos + k
+	}
+	return s
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// Region is a subgraph of the indexed graph.
+type Region struct {
+	// Graph is the subgraph, its IDs are the IDs of the
+	// indexed graph, or its vertex indices if it has no IDs.
+	Graph *parse.Graph
+	// Vertices are the vertices of the indexed graph,
+	// the index is the vertex of the subgraph.
+	Vertices []int
+}
+
+// Region returns the subgraph around the chain c of a sequence
+// of n runes. It has the vertices at most as far from the hits,
+// following the edges in any direction, as the largest run of
+// the sequence out of the hits, plus x.Slack.
+func (x *Index) Region(c Chain, n int) *Region {
+	if len(c.Hits) == 0 {
+		return &Region{Graph: new(parse.Graph)}
+	}
+	first, last := c.Hits[0], c.Hits[len(c.Hits)-1]
+	radius := first.Pos
+	if r := n - last.Pos - x.K; r > radius {
+		radius = r
+	}
+	for i := 1; i < len(c.Hits); i++ {
+		if r := c.Hits[i].Pos - c.Hits[i-1].Pos; r > radius {
+			radius = r
+		}
+	}
+	radius += x.Slack
+
+	dist := make(map[int]int)
+	var queue []int
+	for _, h := range c.Hits {
+		for _, v := range [...]int{h.Start, h.End} {
+			if _, ok := dist[v]; !ok {
+				dist[v] = 0
+				queue = append(queue, v)
+			}
+		}
+	}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		if dist[v] == radius {
+			continue
+		}
+		for _, es := range [...][]int{x.out[v], x.in[v]} {
+			for _, w := range es {
+				if _, ok := dist[w]; !ok {
+					dist[w] = dist[v] + 1
+					queue = append(queue, w)
+				}
+			}
+		}
+	}
+	vertices := make([]int, 0, len(dist))
+	for v := range dist {
+		vertices = append(vertices, v)
+	}
+	sort.Ints(vertices)
+	return x.subgraph(vertices)
+}
+
+// subgraph returns the subgraph induced by vertices, sorted.
+func (x *Index) subgraph(vertices []int) *Region {
+	sub := make(map[int]int, len(vertices))
+	g := &parse.Graph{
+		Nodes: make([]rune, len(vertices)),
+		IDs:   make([]string, len(vertices)),
+	}
+	for i, v := range vertices {
+		sub[v] = i
+		g.Nodes[i] = x.g.Nodes[v]
+		if v < len(x.g.IDs) {
+			g.IDs[i] = x.g.IDs[v]
+		} else {
+			g.IDs[i] = strconv.Itoa(v)
+		}
+	}
+	for i, v := range vertices {
+		for _, w := range x.out[v] {
+			if j, ok := sub[w]; ok {
+				g.Edges = append(g.Edges, [2]int{i, j})
+			}
+		}
+	}
+	return &Region{Graph: g, Vertices: vertices}
+}
+
+// Mapping is the alignment of a sequence to a region.
+type Mapping struct {
+	*alignment.Alignment
+	Region *Region
+	Chain  Chain
+}
+
+// Vertices returns the vertices of the indexed graph
+// visited by the alignment.
+func (m *Mapping) Vertices() []int {
+	nodes := make([]int, len(m.Alignment.Nodes))
+	for i, v := range m.Alignment.Nodes {
+		nodes[i] = m.Region.Vertices[v]
+	}
+	return nodes
+}
+
+// Align aligns seq to the region of the best chain of its hits,
+// the options are the ones of alignment.NewBase. It returns nil
+// if seq has no hits or it cannot be aligned to the region.
+func (x *Index) Align(seq string, score alignment.ScoreFn, opts ...alignment.Option) *Mapping {
+	chains := x.Chains(x.Hits(seq))
+	if len(chains) == 0 {
+		return nil
+	}
+	r := x.Region(chains[0], len([]rune(seq)))
+	g := alignment.NewBase(r.Graph, seq, score, opts...).Graph()
+	a := g.Alignment(g.ShortestPath())
+	if a == nil {
+		return nil
+	}
+	return &Mapping{Alignment: a, Region: r, Chain: chains[0]}
+}
@@ -0,0 +1,132 @@
+package index
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/rschio/align/alignment"
+	"github.com/rschio/align/debruijn"
+	"github.com/rschio/align/parse"
+)
+
+func weight(a, b rune) int64 {
+	if a == b {
+		return 0
+	}
+	return 1
+}
+
+func randomSeq(r *rand.Rand, n int) string {
+	const bases = "ACGT"
+	bs := make([]byte, n)
+	for i := range bs {
+		bs[i] = bases[r.Intn(len(bases))]
+	}
+	return string(bs)
+}
+
+// bubbles returns a chain of the runes of ref with a
+// bubble, an alternative vertex, every 50 vertices.
+func bubbles(ref string) *parse.Graph {
+	g := &parse.Graph{Nodes: []rune(ref)}
+	for i := 1; i < len(ref); i++ {
+		g.Edges = append(g.Edges, [2]int{i - 1, i})
+	}
+	for i := 50; i+1 < len(ref); i += 50 {
+		v := len(g.Nodes)
+		g.Nodes = append(g.Nodes, 'A')
+		g.Edges = append(g.Edges, [2]int{i - 1, v}, [2]int{v, i + 1})
+	}
+	return g
+}
+
+func TestAlign(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	ref := randomSeq(r, 5000)
+	pg := bubbles(ref)
+	x := New(pg, 11)
+
+	seq := []rune(ref[3000:3120])
+	seq[40], seq[80] = 'N', 'N'
+	m := x.Align(string(seq), weight)
+	if m == nil {
+		t.Fatal("want mapping, got nil")
+	}
+	if len(m.Region.Vertices) >= len(pg.Nodes)/2 {
+		t.Fatalf("region too large: %d vertices", len(m.Region.Vertices))
+	}
+	g := alignment.NewBase(pg, string(seq), weight).Graph()
+	_, want := g.ShortestPath()
+	if m.Score != want {
+		t.Fatalf("invalid score want: %d, got: %d", want, m.Score)
+	}
+	vs := m.Vertices()
+	if vs[0] != 3000 || vs[len(vs)-1] != 3119 {
+		t.Fatalf("invalid vertices want: [3000, 3119], got: [%d, %d]", vs[0], vs[len(vs)-1])
+	}
+	if id := m.Region.Graph.IDs[m.Nodes[0]]; id != "3000" {
+		t.Fatalf("invalid ID want: 3000, got: %s", id)
+	}
+}
+
+func TestAlignNoHits(t *testing.T) {
+	pg := bubbles("ACGTACGTACGTACGT")
+	x := New(pg, 8)
+	if m := x.Align("TTTTTTTTTT", weight); m != nil {
+		t.Fatalf("want nil mapping, got score: %d", m.Score)
+	}
+}
+
+func TestFromDeBruijn(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	ref := randomSeq(r, 2000)
+	d := debruijn.NewDeBruijn([]rune(ref), 16)
+	x := FromDeBruijn(d)
+	if len(x.Lookup(ref[100:115])) != 1 {
+		t.Fatalf("k-mer not found: %s", ref[100:115])
+	}
+	m := x.Align(ref[500:600], weight)
+	if m == nil {
+		t.Fatal("want mapping, got nil")
+	}
+	if m.Score != 0 {
+		t.Fatalf("invalid score want: 0, got: %d", m.Score)
+	}
+}
+
+func TestFromCanonicalDeBruijn(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	ref := randomSeq(r, 2000)
+	d := debruijn.NewCanonicalDeBruijn([]rune(ref), 16)
+	x := FromDeBruijn(d)
+	rc := parse.ReverseComplement(ref[500:600])
+	if len(x.Lookup(rc[:15])) != 1 {
+		t.Fatalf("k-mer not found: %s", rc[:15])
+	}
+	m := x.Align(rc, weight)
+	if m == nil {
+		t.Fatal("want mapping, got nil")
+	}
+	if m.Score != 0 {
+		t.Fatalf("invalid score want: 0, got: %d", m.Score)
+	}
+}
+
+func TestWalkDeadEnds(t *testing.T) {
+	// A binary tree of depth 15 has no path of 20 vertices,
+	// every branch ends before spelling a k-mer.
+	g := &parse.Graph{Nodes: []rune{'A'}}
+	for v := 0; len(g.Nodes) < 1<<15-1; v++ {
+		for i := 0; i < 2; i++ {
+			g.Edges = append(g.Edges, [2]int{v, len(g.Nodes)})
+			g.Nodes = append(g.Nodes, 'A')
+		}
+	}
+	x := newIndex(g, 20)
+	var n walkCount
+	x.walk(0, 0, make([]rune, 0, x.K), &n)
+	if n.paths != 0 || n.steps > MaxSteps {
+		t.Fatalf("invalid walk want: 0 paths, at most %d steps, got: %d paths, %d steps",
+			MaxSteps, n.paths, n.steps)
+	}
+}
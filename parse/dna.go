@@ -0,0 +1,34 @@
+package parse
+
+import "unicode"
+
+// complements are the complements of the DNA bases,
+// with the IUPAC ambiguity codes.
+var complements = map[rune]rune{
+	'A': 'T', 'T': 'A', 'U': 'A', 'C': 'G', 'G': 'C',
+	'R': 'Y', 'Y': 'R', 'K': 'M', 'M': 'K', 'S': 'S',
+	'W': 'W', 'B': 'V', 'V': 'B', 'D': 'H', 'H': 'D',
+	'N': 'N',
+}
+
+// Complement returns the complement of the DNA base r, keeping
+// its case. Other runes, as gaps, are their own complement.
+func Complement(r rune) rune {
+	c, ok := complements[unicode.ToUpper(r)]
+	if !ok {
+		return r
+	}
+	if unicode.IsLower(r) {
+		return unicode.ToLower(c)
+	}
+	return c
+}
+
+// ReverseComplement returns the reverse complement of the DNA s.
+func ReverseComplement(s string) string {
+	rs := []rune(s)
+	for i, j := 0, len(rs)-1; i <= j; i, j = i+1, j-1 {
+		rs[i], rs[j] = Complement(rs[j]), Complement(rs[i])
+	}
+	return string(rs)
+}
@@ -0,0 +1,10 @@
+package parse
+
+import "testing"
+
+func TestReverseComplement(t *testing.T) {
+	want := "-YacgtNACGT"
+	if got := ReverseComplement("ACGTNacgtR-"); got != want {
+		t.Fatalf("invalid reverse complement want: %s, got: %s", want, got)
+	}
+}
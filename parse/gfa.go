@@ -0,0 +1,291 @@
+package parse
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// Segment is a GFA segment, its runes are the
+// vertices [Start, End) of the Graph. In a bidirected
+// graph the runes of its reverse complement are the
+// vertices [RevStart, RevEnd), else they are empty.
+type Segment struct {
+	Name             string
+	Start, End       int
+	RevStart, RevEnd int
+}
+
+// Step is a segment of a path with its orientation.
+type Step struct {
+	Segment string
+	Reverse bool
+}
+
+// Path is a GFA path, a named walk over the segments.
+type Path struct {
+	Name  string
+	Steps []Step
+}
+
+type link struct {
+	from, to Step
+	overlap  int
+}
+
+// ParseGFA reads a graph in the GFA 1 format. Each segment is
+// expanded in a chain of vertices, one for each rune, and each
+// link connects the last vertex of a segment to the first vertex
+// of the next one not in the overlap. Only the links keeping the
+// orientation of both segments, +/+ or -/-, are supported.
+func ParseGFA(r io.Reader) (*Graph, error) {
+	return parseGFA(r, false)
+}
+
+// ParseGFABidirected reads a graph in the GFA 1 format as ParseGFA,
+// but each segment is expanded in two chains of vertices, one of its
+// runes and one of their reverse complement, after the chains of all
+// the segments. A link from A in an orientation to B in another one
+// connects the chain of A in the first to the chain of B in the
+// second, and the chain of B in the opposite of the second to the
+// chain of A in the opposite of the first, so every orientation of
+// the links is supported.
+func ParseGFABidirected(r io.Reader) (*Graph, error) {
+	return parseGFA(r, true)
+}
+
+func parseGFA(r io.Reader, bidirected bool) (*Graph, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<30)
+	var segs []Segment
+	var labels [][]rune
+	var links []link
+	var paths []Path
+	for sc.Scan() {
+		line := sc.Bytes()
+		bs := bytes.TrimSpace(line)
+		if len(bs) == 0 {
+			continue
+		}
+		fields := bytes.Split(bs, []byte("\t"))
+		var err error
+		switch string(fields[0]) {
+		case "S":
+			var s Segment
+			var label []rune
+			s, label, err = parseSegment(fields)
+			segs = append(segs, s)
+			labels = append(labels, label)
+		case "L":
+			var l link
+			l, err = parseLink(fields)
+			links = append(links, l)
+		case "P":
+			var p Path
+			p, err = parsePath(fields)
+			paths = append(paths, p)
+		}
+		// Other records are ignored.
+		if err != nil {
+			return nil, fmt.Errorf("invalid line: %s: %v", line, err)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return buildGFA(segs, labels, links, paths, bidirected)
+}
+
+func parseSegment(fields [][]byte) (Segment, []rune, error) {
+	if len(fields) < 3 {
+		return Segment{}, nil, fmt.Errorf("want 3 fields, got: %d", len(fields))
+	}
+	if string(fields[2]) == "*" || len(fields[2]) == 0 {
+		return Segment{}, nil, fmt.Errorf("segment without sequence")
+	}
+	label := []rune(string(fields[2]))
+	return Segment{Name: string(fields[1])}, label, nil
+}
+
+func parseLink(fields [][]byte) (link, error) {
+	if len(fields) < 6 {
+		return link{}, fmt.Errorf("want 6 fields, got: %d", len(fields))
+	}
+	from, err := parseStep(fields[1], fields[2])
+	if err != nil {
+		return link{}, err
+	}
+	to, err := parseStep(fields[3], fields[4])
+	if err != nil {
+		return link{}, err
+	}
+	overlap, err := parseOverlap(fields[5])
+	if err != nil {
+		return link{}, err
+	}
+	return link{from: from, to: to, overlap: overlap}, nil
+}
+
+func parseStep(name, orient []byte) (Step, error) {
+	switch string(orient) {
+	case "+":
+		return Step{Segment: string(name)}, nil
+	case "-":
+		return Step{Segment: string(name), Reverse: true}, nil
+	}
+	return Step{}, fmt.Errorf("invalid orientation: %s", orient)
+}
+
+// parseOverlap parses an overlap made only of matches,
+// nM, where * is an unknown overlap, taken as 0.
+func parseOverlap(bs []byte) (int, error) {
+	if string(bs) == "*" {
+		return 0, nil
+	}
+	if len(bs) < 2 || bs[len(bs)-1] != 'M' {
+		return 0, fmt.Errorf("unsupported overlap: %s", bs)
+	}
+	n, err := strconv.Atoi(string(bs[:len(bs)-1]))
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("unsupported overlap: %s", bs)
+	}
+	return n, nil
+}
+
+func parsePath(fields [][]byte) (Path, error) {
+	if len(fields) < 3 {
+		return Path{}, fmt.Errorf("want 3 fields, got: %d", len(fields))
+	}
+	p := Path{Name: string(fields[1])}
+	for _, s := range bytes.Split(fields[2], []byte(",")) {
+		if len(s) < 2 {
+			return Path{}, fmt.Errorf("invalid step: %s", s)
+		}
+		step, err := parseStep(s[:len(s)-1], s[len(s)-1:])
+		if err != nil {
+			return Path{}, err
+		}
+		p.Steps = append(p.Steps, step)
+	}
+	return p, nil
+}
+
+func buildGFA(segs []Segment, labels [][]rune, links []link, paths []Path, bidirected bool) (*Graph, error) {
+	g := &Graph{Segments: segs, Paths: paths}
+	index := make(map[string]int, len(segs))
+	chain := func(name string, label []rune) (start, end int) {
+		start = len(g.Nodes)
+		for j, r := range label {
+			v := len(g.Nodes)
+			g.Nodes = append(g.Nodes, r)
+			g.IDs = append(g.IDs, name+":"+strconv.Itoa(j))
+			if j > 0 {
+				g.Edges = append(g.Edges, [2]int{v - 1, v})
+			}
+		}
+		return start, len(g.Nodes)
+	}
+	for i := range segs {
+		s := &segs[i]
+		if _, exist := index[s.Name]; exist {
+			return nil, fmt.Errorf("duplicated segment: %s", s.Name)
+		}
+		index[s.Name] = i
+		s.Start, s.End = chain(s.Name, labels[i])
+	}
+	if bidirected {
+		for i := range segs {
+			s := &segs[i]
+			rc := []rune(ReverseComplement(string(labels[i])))
+			s.RevStart, s.RevEnd = chain(s.Name+"-", rc)
+		}
+	}
+	// bounds returns the chain of the step.
+	bounds := func(s Step) (start, end int) {
+		seg := &segs[index[s.Segment]]
+		if s.Reverse {
+			return seg.RevStart, seg.RevEnd
+		}
+		return seg.Start, seg.End
+	}
+	link := func(from, to Step, overlap int) {
+		_, end := bounds(from)
+		start, _ := bounds(to)
+		g.Edges = append(g.Edges, [2]int{end - 1, start + overlap})
+	}
+	for _, l := range links {
+		from, to := l.from, l.to
+		if !bidirected && from.Reverse != to.Reverse {
+			return nil, fmt.Errorf("unsupported link orientation: %s %s",
+				from.Segment, to.Segment)
+		}
+		if !bidirected && from.Reverse {
+			// A- B- is the same link of B+ A+.
+			from, to = to, from
+			from.Reverse, to.Reverse = false, false
+		}
+		u, ok := index[from.Segment]
+		if !ok {
+			return nil, fmt.Errorf("unknown segment: %s", from.Segment)
+		}
+		w, ok := index[to.Segment]
+		if !ok {
+			return nil, fmt.Errorf("unknown segment: %s", to.Segment)
+		}
+		if l.overlap >= segs[w].End-segs[w].Start {
+			return nil, fmt.Errorf("overlap longer than segment: %s", to.Segment)
+		}
+		// The other strand enters from at the overlap.
+		if bidirected && l.overlap >= segs[u].End-segs[u].Start {
+			return nil, fmt.Errorf("overlap longer than segment: %s", from.Segment)
+		}
+		link(from, to, l.overlap)
+		if bidirected {
+			// The same link read in the other strand.
+			link(flip(to), flip(from), l.overlap)
+		}
+	}
+	for _, p := range paths {
+		for _, s := range p.Steps {
+			if _, ok := index[s.Segment]; !ok {
+				return nil, fmt.Errorf("unknown segment in path %s: %s", p.Name, s.Segment)
+			}
+		}
+	}
+	return g, nil
+}
+
+func flip(s Step) Step {
+	s.Reverse = !s.Reverse
+	return s
+}
+
+// Segment returns the segment of the vertex v and the offset of
+// v in it, in the orientation of v. It returns nil if the graph
+// has no segments.
+func (g *Graph) Segment(v int) (*Segment, int) {
+	i := sort.Search(len(g.Segments), func(i int) bool {
+		return g.Segments[i].End > v
+	})
+	if i < len(g.Segments) {
+		s := &g.Segments[i]
+		return s, v - s.Start
+	}
+	i = sort.Search(len(g.Segments), func(i int) bool {
+		return g.Segments[i].RevEnd > v
+	})
+	if i < len(g.Segments) && g.Segments[i].RevStart <= v {
+		s := &g.Segments[i]
+		return s, v - s.RevStart
+	}
+	return nil, 0
+}
+
+// IsReverse tells if the vertex v is a rune of the
+// reverse complement of s.
+func (s *Segment) IsReverse(v int) bool {
+	return s.RevStart <= v && v < s.RevEnd
+}
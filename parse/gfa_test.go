@@ -0,0 +1,93 @@
+package parse
+
+import (
+	"strings"
+	"testing"
+)
+
+const gfa = "H\tVN:Z:1.0\n" +
+	"S\ts1\tACG\n" +
+	"S\ts2\tTT\n" +
+	"S\ts3\tGCA\n" +
+	"L\ts1\t+\ts2\t+\t0M\n" +
+	"L\ts3\t-\ts2\t-\t1M\n" +
+	"P\tp1\ts1+,s2+,s3+\t*\n"
+
+func TestParseGFA(t *testing.T) {
+	g, err := ParseGFA(strings.NewReader(gfa))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(g.Nodes); got != "ACGTTGCA" {
+		t.Fatalf("invalid nodes want: %s, got: %s", "ACGTTGCA", got)
+	}
+	want := [][2]int{{0, 1}, {1, 2}, {3, 4}, {5, 6}, {6, 7}, {2, 3}, {4, 6}}
+	if len(g.Edges) != len(want) {
+		t.Fatalf("invalid edges want: %v, got: %v", want, g.Edges)
+	}
+	for i := range want {
+		if g.Edges[i] != want[i] {
+			t.Fatalf("invalid edges want: %v, got: %v", want, g.Edges)
+		}
+	}
+	s, off := g.Segment(4)
+	if s.Name != "s2" || off != 1 {
+		t.Fatalf("invalid segment want: s2 1, got: %s %d", s.Name, off)
+	}
+	if len(g.Paths) != 1 || len(g.Paths[0].Steps) != 3 {
+		t.Fatalf("invalid paths: %v", g.Paths)
+	}
+}
+
+func TestParseGFAErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{name: "no sequence", in: "S\ts1\t*\n"},
+		{name: "empty sequence", in: "S\tA\tAC\nS\tE\t\tLN:i:0\nL\tE\t+\tA\t+\t0M\n"},
+		{name: "unknown segment", in: "S\ts1\tA\nL\ts1\t+\ts2\t+\t0M\n"},
+		{name: "orientation", in: "S\ts1\tA\nS\ts2\tC\nL\ts1\t+\ts2\t-\t0M\n"},
+		{name: "overlap", in: "S\ts1\tA\nS\ts2\tC\nL\ts1\t+\ts2\t+\t1M\n"},
+		{name: "cigar", in: "S\ts1\tA\nS\ts2\tCC\nL\ts1\t+\ts2\t+\t1M1I\n"},
+	}
+	for _, tt := range tests {
+		if _, err := ParseGFA(strings.NewReader(tt.in)); err == nil {
+			t.Errorf("%s: want error, got nil", tt.name)
+		}
+	}
+}
+
+func TestParseGFABidirected(t *testing.T) {
+	in := "S\ts1\tACG\nS\ts2\tTTC\nL\ts1\t+\ts2\t-\t0M\n"
+	g, err := ParseGFABidirected(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(g.Nodes); got != "ACGTTCCGTGAA" {
+		t.Fatalf("invalid nodes want: %s, got: %s", "ACGTTCCGTGAA", got)
+	}
+	// s1+ -> s2- and s2+ -> s1-.
+	want := map[[2]int]bool{{2, 9}: true, {5, 6}: true}
+	for _, e := range g.Edges {
+		if e[1] != e[0]+1 && !want[e] {
+			t.Fatalf("unexpected edge: %v", e)
+		}
+		delete(want, e)
+	}
+	if len(want) != 0 {
+		t.Fatalf("missing edges: %v", want)
+	}
+	s, off := g.Segment(10)
+	if s == nil || s.Name != "s2" || off != 1 || !s.IsReverse(10) {
+		t.Fatalf("invalid segment of 10 want: s2 1 reverse, got: %v %d", s, off)
+	}
+	if _, err := ParseGFA(strings.NewReader(in)); err == nil {
+		t.Fatal("want error for a +/- link, got nil")
+	}
+	// The other strand of the link overlaps the short A.
+	in = "S\tA\tAC\nS\tB\tGGGGGGGGGG\nL\tA\t+\tB\t+\t3M\n"
+	if _, err := ParseGFABidirected(strings.NewReader(in)); err == nil {
+		t.Fatal("want error for an overlap longer than from, got nil")
+	}
+}
@@ -27,6 +27,13 @@ type Graph struct {
 	// and the value is the node's letter.
 	Nodes []rune
 	Edges [][2]int
+	// IDs store the original node IDs, the index is
+	// the same of Nodes.
+	IDs []string
+	// Segments and Paths are the segments and paths
+	// of graphs read from GFA.
+	Segments []Segment
+	Paths    []Path
 }
 
 func Parse(r io.Reader) (*Graph, error) {
@@ -52,9 +59,11 @@ func (g *GraphBuilder) Build() (*Graph, error) {
 		uniqLabels[r] = struct{}{}
 	}
 	nodes := make([]rune, len(g.Nodes))
+	ids := make([]string, len(g.Nodes))
 	for i, n := range g.Nodes {
 		r, _ := utf8.DecodeRuneInString(n.Label)
 		nodes[i] = r
+		ids[i] = n.ID
 	}
 	edges := make([][2]int, len(g.Edges))
 	for i, e := range g.Edges {
@@ -64,6 +73,7 @@ func (g *GraphBuilder) Build() (*Graph, error) {
 	return &Graph{
 		Nodes: nodes,
 		Edges: edges,
+		IDs:   ids,
 	}, nil
 }
 
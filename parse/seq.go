@@ -0,0 +1,104 @@
+package parse
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"unicode/utf8"
+)
+
+// SeqGraph is a graph whose nodes are labeled by strings. Each edge
+// links the last rune of a node to the rune Overlap of the next
+// one, so the runes of a node need not be vertices of their own.
+type SeqGraph struct {
+	Labels []string
+	Edges  [][2]int
+	// IDs store the original node IDs, the index is
+	// the same of Labels.
+	IDs []string
+	// Overlap is the number of runes the last runes of a node
+	// share with the first runes of the nodes after it, they are
+	// skipped by the edges.
+	Overlap int
+}
+
+// ParseSeq reads a graph as Parse, but the node labels may
+// have many runes.
+func ParseSeq(r io.Reader) (*SeqGraph, error) {
+	gb, err := parse(r)
+	if err != nil {
+		return nil, err
+	}
+	return gb.BuildSeq()
+}
+
+// BuildSeq builds a graph as Build, but the node labels may
+// have many runes.
+func (g *GraphBuilder) BuildSeq() (*SeqGraph, error) {
+	index := make(map[string]int)
+	sg := &SeqGraph{
+		Labels: make([]string, len(g.Nodes)),
+		IDs:    make([]string, len(g.Nodes)),
+		Edges:  make([][2]int, len(g.Edges)),
+	}
+	for i, n := range g.Nodes {
+		if _, exist := index[n.ID]; exist {
+			return nil, fmt.Errorf("duplicated node: %s", n.ID)
+		}
+		if n.Label == "" {
+			return nil, fmt.Errorf("empty label: %s", n.ID)
+		}
+		index[n.ID] = i
+		sg.Labels[i], sg.IDs[i] = n.Label, n.ID
+	}
+	for i, e := range g.Edges {
+		sg.Edges[i] = [2]int{index[e.From], index[e.To]}
+	}
+	return sg, nil
+}
+
+// Validate checks that every label is longer than the overlap
+// and that the edges link nodes of the graph.
+func (g *SeqGraph) Validate() error {
+	if g.Overlap < 0 {
+		return fmt.Errorf("invalid overlap: %d", g.Overlap)
+	}
+	for i, l := range g.Labels {
+		if n := utf8.RuneCountInString(l); n <= g.Overlap {
+			return fmt.Errorf("invalid label: %d: %d runes, overlap: %d", i, n, g.Overlap)
+		}
+	}
+	for _, e := range g.Edges {
+		if e[0] < 0 || e[0] >= len(g.Labels) || e[1] < 0 || e[1] >= len(g.Labels) {
+			return fmt.Errorf("invalid edge: %v", e)
+		}
+	}
+	return nil
+}
+
+// Expand returns the graph with a vertex for each rune, named by
+// the ID of its node and its offset, as the segments of ParseGFA.
+func (g *SeqGraph) Expand() *Graph {
+	pg := new(Graph)
+	start := make([]int, len(g.Labels)+1)
+	for i, l := range g.Labels {
+		start[i] = len(pg.Nodes)
+		id := strconv.Itoa(i)
+		if i < len(g.IDs) {
+			id = g.IDs[i]
+		}
+		for j, r := range []rune(l) {
+			v := len(pg.Nodes)
+			pg.Nodes = append(pg.Nodes, r)
+			pg.IDs = append(pg.IDs, id+":"+strconv.Itoa(j))
+			if j > 0 {
+				pg.Edges = append(pg.Edges, [2]int{v - 1, v})
+			}
+		}
+	}
+	start[len(g.Labels)] = len(pg.Nodes)
+	for _, e := range g.Edges {
+		pg.Edges = append(pg.Edges, [2]int{start[e[0]+1] - 1, start[e[1]] + g.Overlap})
+	}
+	return pg
+}
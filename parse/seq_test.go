@@ -0,0 +1,41 @@
+package parse
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSeq(t *testing.T) {
+	in := "(a,ACG)\n(b,T)\n(c,GCA)\n{a,b}\n{a,c}\n{b,c}\n"
+	g, err := ParseSeq(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	pg := g.Expand()
+	if got := string(pg.Nodes); got != "ACGTGCA" {
+		t.Fatalf("invalid nodes want: %s, got: %s", "ACGTGCA", got)
+	}
+	want := [][2]int{{0, 1}, {1, 2}, {4, 5}, {5, 6}, {2, 3}, {2, 4}, {3, 4}}
+	if len(pg.Edges) != len(want) {
+		t.Fatalf("invalid edges want: %v, got: %v", want, pg.Edges)
+	}
+	for i := range want {
+		if pg.Edges[i] != want[i] {
+			t.Fatalf("invalid edges want: %v, got: %v", want, pg.Edges)
+		}
+	}
+	if pg.IDs[5] != "c:1" {
+		t.Fatalf("invalid ID want: c:1, got: %s", pg.IDs[5])
+	}
+
+	g.Overlap = 1
+	if err := g.Validate(); err == nil {
+		t.Fatal("want error for a label not longer than the overlap, got nil")
+	}
+	if _, err := ParseSeq(strings.NewReader("(a,)\n")); err == nil {
+		t.Fatal("want error for an empty label, got nil")
+	}
+}